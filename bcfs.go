@@ -0,0 +1,132 @@
+package parsegp
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+)
+
+const (
+	bcfsSectorSize     = 4096
+	bcfsFileNameLength = 127
+)
+
+// BCFS is the virtual filesystem packed inside a decompressed BCFZ blob.
+// Guitar Pro uses it to bundle several named files (score.gpif, misc.xml,
+// PartConfiguration, ...) into one .gpx archive.
+type BCFS struct {
+	Files map[string]*BCFSFile
+}
+
+// BCFSFile is a single named entry inside a BCFS container. It implements
+// io.Reader and io.ReaderAt over the concatenation of its sectors, capped
+// at Size.
+type BCFSFile struct {
+	Name string
+	Size int32
+
+	data   []byte
+	offset int64
+}
+
+func (f *BCFSFile) Read(p []byte) (int, error) {
+	if f.offset >= int64(len(f.data)) {
+		return 0, io.EOF
+	}
+	n := copy(p, f.data[f.offset:])
+	f.offset += int64(n)
+	return n, nil
+}
+
+func (f *BCFSFile) ReadAt(p []byte, off int64) (int, error) {
+	if off < 0 {
+		return 0, fmt.Errorf("bcfs: negative ReadAt offset")
+	}
+	if off >= int64(len(f.data)) {
+		return 0, io.EOF
+	}
+	n := copy(p, f.data[off:])
+	if n < len(p) {
+		return n, io.EOF
+	}
+	return n, nil
+}
+
+// ParseBCFS is the exported form of parseBCFS, for packages outside
+// parsegp (such as gpif) that need to pull a named entry, e.g.
+// "Content/score.gpif", out of an already BCFZ-decompressed blob.
+func ParseBCFS(data []byte) (*BCFS, error) {
+	return parseBCFS(data)
+}
+
+// parseBCFS reads the BCFS virtual filesystem that follows the BCFZ
+// decompression step. Each 4096-byte sector starts with a 4-byte record
+// type, a 127-byte NUL-padded file name, a 4-byte file size, and a chain
+// of 4-byte sector indices (terminated by 0) pointing at the sectors that
+// hold the file's data; a second kind of sector (continuation) carries
+// only data for an already-described file.
+func parseBCFS(data []byte) (*BCFS, error) {
+	if len(data) < 4 || !bytes.HasPrefix(data, []byte("BCFS")) {
+		return nil, fmt.Errorf("bcfs: missing BCFS magic")
+	}
+
+	sectorCount := len(data) / bcfsSectorSize
+	sector := func(i int) []byte {
+		start := i * bcfsSectorSize
+		end := start + bcfsSectorSize
+		if end > len(data) {
+			end = len(data)
+		}
+		return data[start:end]
+	}
+
+	bcfs := &BCFS{Files: make(map[string]*BCFSFile)}
+
+	for i := 1; i < sectorCount; i++ {
+		s := sector(i)
+		if len(s) < 4+bcfsFileNameLength+4 {
+			continue
+		}
+
+		recordType := int32(binary.LittleEndian.Uint32(s[0:4]))
+		if recordType != 1 {
+			// Not a "file header" sector (e.g. a continuation/data sector
+			// referenced by an earlier chain, or an unused sector); skip it.
+			continue
+		}
+
+		name := string(bytes.TrimRight(s[4:4+bcfsFileNameLength], "\x00"))
+		if name == "" {
+			continue
+		}
+		size := int32(binary.LittleEndian.Uint32(s[4+bcfsFileNameLength : 4+bcfsFileNameLength+4]))
+
+		chainOffset := 4 + bcfsFileNameLength + 4
+		var buf bytes.Buffer
+		for chainOffset+4 <= len(s) && buf.Len() < int(size) {
+			sectorIndex := int32(binary.LittleEndian.Uint32(s[chainOffset : chainOffset+4]))
+			chainOffset += 4
+			if sectorIndex == 0 {
+				break
+			}
+			if int(sectorIndex) >= sectorCount {
+				return nil, fmt.Errorf("bcfs: file %q references out-of-range sector %d", name, sectorIndex)
+			}
+			buf.Write(sector(int(sectorIndex)))
+		}
+
+		fileData := buf.Bytes()
+		if int64(len(fileData)) > int64(size) {
+			fileData = fileData[:size]
+		}
+
+		bcfs.Files[name] = &BCFSFile{
+			Name: name,
+			Size: size,
+			data: fileData,
+		}
+	}
+
+	return bcfs, nil
+}
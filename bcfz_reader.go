@@ -0,0 +1,176 @@
+package parsegp
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+	"sync"
+)
+
+const bcfzWindowSize = 32 * 1024
+
+var bcfzWindowPool = sync.Pool{
+	New: func() any { return make([]byte, bcfzWindowSize) },
+}
+
+// BCFZReader decompresses a BCFZ stream incrementally instead of
+// materializing the whole payload in one buffer. Back-references
+// are resolved against a fixed-size circular window (capped at
+// expectedLength for small payloads) pooled via sync.Pool, so a large score
+// does not need an allocation the size of its decompressed output just to
+// decode it.
+type BCFZReader struct {
+	bits           *BitStream
+	expectedLength uint32
+	written        uint32
+
+	window    []byte
+	windowLen int // number of valid bytes currently in window (<= len(window))
+	windowPos int // next write position, wraps around len(window)
+
+	pending []byte // bytes decoded but not yet returned to the caller
+	done    bool
+}
+
+// NewBCFZReader reads the BCFZ length header from r and returns a reader
+// that produces the decompressed bytes on demand.
+func NewBCFZReader(r io.Reader) (*BCFZReader, error) {
+	var expectedLength uint32
+	if err := binary.Read(r, binary.LittleEndian, &expectedLength); err != nil {
+		return nil, err
+	}
+
+	windowSize := bcfzWindowSize
+	if expectedLength > 0 && int(expectedLength) < windowSize {
+		windowSize = int(expectedLength)
+	}
+
+	window := bcfzWindowPool.Get().([]byte)
+	if cap(window) < windowSize {
+		window = make([]byte, windowSize)
+	}
+
+	return &BCFZReader{
+		bits:           newBitStream(r),
+		expectedLength: expectedLength,
+		window:         window[:windowSize],
+	}, nil
+}
+
+// Close returns the BCFZReader's window buffer to the shared pool. It is
+// safe, but not required, to call once the caller is done reading.
+func (z *BCFZReader) Close() error {
+	if z.window != nil {
+		bcfzWindowPool.Put(z.window[:cap(z.window)]) //nolint:staticcheck // reuse full backing array
+		z.window = nil
+	}
+	return nil
+}
+
+func (z *BCFZReader) emit(b byte) {
+	z.window[z.windowPos] = b
+	z.windowPos = (z.windowPos + 1) % len(z.window)
+	if z.windowLen < len(z.window) {
+		z.windowLen++
+	}
+	z.pending = append(z.pending, b)
+	z.written++
+}
+
+// windowByteAt returns the byte `back` positions behind the most recently
+// emitted byte (back=1 is the previous byte).
+func (z *BCFZReader) windowByteAt(back int) (byte, error) {
+	if back <= 0 || back > z.windowLen {
+		return 0, &BCFZError{Op: "back-reference", Offset: back, WindowLen: z.windowLen}
+	}
+	idx := (z.windowPos - back + len(z.window)) % len(z.window)
+	return z.window[idx], nil
+}
+
+func (z *BCFZReader) decodeChunk() error {
+	chunkType, err := z.bits.ReadBits(1)
+	if err != nil {
+		return err
+	}
+
+	if chunkType == 0 {
+		length, err := z.bits.ReadBits(2)
+		if err != nil {
+			return err
+		}
+		z.bits.Align()
+		for i := uint32(0); i < length; i++ {
+			b, err := z.bits.br.ReadByte()
+			if err != nil {
+				return err
+			}
+			z.emit(b)
+		}
+		return nil
+	}
+
+	wordSize, err := z.bits.ReadBits(4)
+	if err != nil {
+		return err
+	}
+	offset, err := z.bits.ReadBits(uint(wordSize))
+	if err != nil {
+		return err
+	}
+	length, err := z.bits.ReadBits(uint(wordSize))
+	if err != nil {
+		return err
+	}
+	if int(offset) > z.windowLen {
+		return &BCFZError{Op: "back-reference", Offset: int(offset), Length: int(length), WindowLen: z.windowLen}
+	}
+
+	for i := uint32(0); i < length; i++ {
+		b, err := z.windowByteAt(int(offset))
+		if err != nil {
+			return err
+		}
+		z.emit(b)
+	}
+	return nil
+}
+
+// Read implements io.Reader, producing decompressed bytes incrementally.
+func (z *BCFZReader) Read(p []byte) (int, error) {
+	for len(z.pending) == 0 {
+		if z.done || z.written >= z.expectedLength {
+			z.done = true
+			return 0, io.EOF
+		}
+		if err := z.decodeChunk(); err != nil {
+			z.done = true
+			if err == io.EOF && z.written != z.expectedLength {
+				return 0, &BCFZError{Op: "length-mismatch", Length: int(z.written), WindowLen: int(z.expectedLength)}
+			}
+			return 0, err
+		}
+	}
+
+	n := copy(p, z.pending)
+	z.pending = z.pending[n:]
+	return n, nil
+}
+
+// BCFZError reports a specific failure mode decoding a BCFZ stream:
+// truncation, a back-reference pointing outside the window, or a
+// decompressed size that does not match the length header.
+type BCFZError struct {
+	Op        string // "back-reference", "length-mismatch"
+	Offset    int
+	Length    int
+	WindowLen int
+}
+
+func (e *BCFZError) Error() string {
+	switch e.Op {
+	case "length-mismatch":
+		return fmt.Sprintf("bcfz: decompressed %d bytes, expected %d", e.Length, e.WindowLen)
+	default:
+		return fmt.Sprintf("bcfz: %s offset=%d length=%d window=%d", e.Op, e.Offset, e.Length, e.WindowLen)
+	}
+}
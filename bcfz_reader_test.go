@@ -0,0 +1,123 @@
+package parsegp
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io"
+	"testing"
+)
+
+// bitWriter packs bits MSB-first into bytes, mirroring how BitStream reads
+// them, so tests can hand-assemble a BCFZ chunk stream byte for byte.
+type bitWriter struct {
+	buf   []byte
+	cur   byte
+	nbits uint
+}
+
+func (w *bitWriter) writeBits(v uint32, n uint) {
+	for i := int(n) - 1; i >= 0; i-- {
+		w.cur = w.cur<<1 | byte((v>>uint(i))&1)
+		w.nbits++
+		if w.nbits == 8 {
+			w.buf = append(w.buf, w.cur)
+			w.cur, w.nbits = 0, 0
+		}
+	}
+}
+
+// align pads any partial byte with zero bits and flushes it, the same way
+// BCFZReader's Align call discards a literal chunk's padding before its raw
+// bytes.
+func (w *bitWriter) align() {
+	if w.nbits > 0 {
+		w.cur <<= 8 - w.nbits
+		w.buf = append(w.buf, w.cur)
+		w.cur, w.nbits = 0, 0
+	}
+}
+
+// bcfzStream prepends the little-endian decompressed-length header
+// NewBCFZReader expects in front of body.
+func bcfzStream(expectedLength uint32, body []byte) []byte {
+	header := make([]byte, 4)
+	binary.LittleEndian.PutUint32(header, expectedLength)
+	return append(header, body...)
+}
+
+func TestBCFZReaderLiteralChunk(t *testing.T) {
+	var w bitWriter
+	w.writeBits(0, 1) // literal chunk
+	w.writeBits(3, 2) // 3 raw bytes follow
+	w.align()
+	w.buf = append(w.buf, 'a', 'b', 'c')
+
+	z, err := NewBCFZReader(bytes.NewReader(bcfzStream(3, w.buf)))
+	if err != nil {
+		t.Fatalf("NewBCFZReader: %v", err)
+	}
+	defer z.Close()
+
+	got, err := io.ReadAll(z)
+	if err != nil {
+		t.Fatalf("ReadAll: %v", err)
+	}
+	if string(got) != "abc" {
+		t.Fatalf("decompressed = %q, want %q", got, "abc")
+	}
+}
+
+func TestBCFZReaderBackReference(t *testing.T) {
+	var w bitWriter
+	w.writeBits(0, 1) // literal chunk
+	w.writeBits(2, 2) // 2 raw bytes follow
+	w.align()
+	w.buf = append(w.buf, 'a', 'b')
+
+	w.writeBits(1, 1) // compressed chunk
+	w.writeBits(4, 4) // wordSize = 4 bits
+	w.writeBits(2, 4) // offset = 2 (back to the start of "ab")
+	w.writeBits(2, 4) // length = 2
+	w.align()
+
+	z, err := NewBCFZReader(bytes.NewReader(bcfzStream(4, w.buf)))
+	if err != nil {
+		t.Fatalf("NewBCFZReader: %v", err)
+	}
+	defer z.Close()
+
+	got, err := io.ReadAll(z)
+	if err != nil {
+		t.Fatalf("ReadAll: %v", err)
+	}
+	if string(got) != "abab" {
+		t.Fatalf("decompressed = %q, want %q", got, "abab")
+	}
+}
+
+func TestBCFZReaderBackReferenceOutsideWindowErrors(t *testing.T) {
+	var w bitWriter
+	w.writeBits(0, 1) // literal chunk
+	w.writeBits(1, 2) // 1 raw byte follows
+	w.align()
+	w.buf = append(w.buf, 'a')
+
+	w.writeBits(1, 1) // compressed chunk
+	w.writeBits(4, 4) // wordSize = 4 bits
+	w.writeBits(5, 4) // offset = 5, but only 1 byte has been emitted so far
+	w.writeBits(1, 4) // length = 1
+	w.align()
+
+	z, err := NewBCFZReader(bytes.NewReader(bcfzStream(2, w.buf)))
+	if err != nil {
+		t.Fatalf("NewBCFZReader: %v", err)
+	}
+	defer z.Close()
+
+	_, err = io.ReadAll(z)
+	var bcfzErr *BCFZError
+	if !errors.As(err, &bcfzErr) || bcfzErr.Op != "back-reference" {
+		t.Fatalf("ReadAll error = %v, want a back-reference *BCFZError", err)
+	}
+}
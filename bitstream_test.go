@@ -0,0 +1,81 @@
+package parsegp
+
+import (
+	"bytes"
+	"io"
+	"testing"
+)
+
+func TestBitStreamReadBits(t *testing.T) {
+	// 0b10110010, 0b11110000
+	bs := newBitStream(bytes.NewReader([]byte{0xB2, 0xF0}))
+
+	if v, err := bs.ReadBits(3); err != nil || v != 0b101 {
+		t.Fatalf("ReadBits(3) = %v, %v, want 0b101, nil", v, err)
+	}
+	if v, err := bs.ReadBits(5); err != nil || v != 0b10010 {
+		t.Fatalf("ReadBits(5) = %v, %v, want 0b10010, nil", v, err)
+	}
+	if v, err := bs.ReadBits(4); err != nil || v != 0b1111 {
+		t.Fatalf("ReadBits(4) = %v, %v, want 0b1111, nil", v, err)
+	}
+	if v, err := bs.ReadBits(4); err != nil || v != 0b0000 {
+		t.Fatalf("ReadBits(4) = %v, %v, want 0, nil", v, err)
+	}
+	if _, err := bs.ReadBits(1); err != io.EOF {
+		t.Fatalf("ReadBits past end = %v, want io.EOF", err)
+	}
+}
+
+func TestBitStreamPeekDoesNotConsume(t *testing.T) {
+	bs := newBitStream(bytes.NewReader([]byte{0xAA}))
+
+	peeked, err := bs.PeekBits(4)
+	if err != nil || peeked != 0b1010 {
+		t.Fatalf("PeekBits(4) = %v, %v, want 0b1010, nil", peeked, err)
+	}
+	read, err := bs.ReadBits(4)
+	if err != nil || read != peeked {
+		t.Fatalf("ReadBits(4) after Peek = %v, %v, want %v, nil", read, err, peeked)
+	}
+}
+
+func TestBitStreamAlign(t *testing.T) {
+	bs := newBitStream(bytes.NewReader([]byte{0xFF, 0x00, 0x42}))
+
+	if _, err := bs.ReadBits(3); err != nil {
+		t.Fatalf("ReadBits(3): %v", err)
+	}
+	bs.Align()
+	if bs.numBits != 0 {
+		t.Fatalf("numBits after Align = %d, want 0", bs.numBits)
+	}
+	// Align discarded the remaining 5 bits of the first byte (0xFF), so the
+	// next read starts at the second byte (0x00), not the third (0x42).
+	if v, err := bs.ReadBits(8); err != nil || v != 0x00 {
+		t.Fatalf("ReadBits(8) after Align = %v, %v, want 0x00, nil", v, err)
+	}
+	if v, err := bs.ReadBits(8); err != nil || v != 0x42 {
+		t.Fatalf("ReadBits(8) = %v, %v, want 0x42, nil", v, err)
+	}
+}
+
+func TestBitStreamOffsetCountsBufferedBits(t *testing.T) {
+	bs := newBitStream(bytes.NewReader([]byte{0x01, 0x02}))
+
+	if _, err := bs.ReadBits(3); err != nil {
+		t.Fatalf("ReadBits(3): %v", err)
+	}
+	if bs.Offset() != 8 {
+		t.Fatalf("Offset = %d, want 8 (one byte pulled into the accumulator)", bs.Offset())
+	}
+	if _, err := bs.ReadBits(5); err != nil {
+		t.Fatalf("ReadBits(5): %v", err)
+	}
+	if _, err := bs.ReadBits(1); err != nil {
+		t.Fatalf("ReadBits(1): %v", err)
+	}
+	if bs.Offset() != 16 {
+		t.Fatalf("Offset = %d, want 16 after pulling a second byte", bs.Offset())
+	}
+}
@@ -0,0 +1,521 @@
+package parsegp
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+)
+
+// bodyReader wraps the file handle used by LoadHeader so the rest of the
+// file (measures, tracks, beats, notes) can be decoded with the same
+// stream-based approach instead of loading the whole file into memory
+// like Parser does.
+type bodyReader struct {
+	fo io.ReadSeeker
+}
+
+func (b *bodyReader) readByte() (byte, error) {
+	buf := make([]byte, 1)
+	if _, err := io.ReadFull(b.fo, buf); err != nil {
+		return 0, err
+	}
+	return buf[0], nil
+}
+
+func (b *bodyReader) readBool() (bool, error) {
+	v, err := b.readByte()
+	return v != 0, err
+}
+
+func (b *bodyReader) readInt32() (int32, error) {
+	var v int32
+	if err := binary.Read(b.fo, binary.LittleEndian, &v); err != nil {
+		return 0, err
+	}
+	return v, nil
+}
+
+func (b *bodyReader) skip(n int64) error {
+	_, err := b.fo.Seek(n, io.SeekCurrent)
+	return err
+}
+
+// Load reads both the header and the body of the Guitar Pro file.
+// It is a convenience wrapper around LoadHeader and LoadBody so callers
+// that want a fully populated GPFile do not have to call both themselves.
+func (gp *GPFile) Load() error {
+	if err := gp.LoadHeader(); err != nil {
+		return err
+	}
+	return gp.LoadBody()
+}
+
+// LoadBody decodes everything that follows the metadata block read by
+// LoadHeader: the lyric track, tempo and key signature, the MIDI channel
+// table, measure headers, track descriptors and the per-track/per-measure
+// beats and notes. It reopens the file and re-runs LoadHeader's seeking
+// logic, since LoadHeader does not keep the file handle open once it
+// returns.
+//
+// Version differences (gp3 has no triplet feel flag, gp5 carries a second
+// voice and an extra directions/chord-diagram block) are handled inline
+// via gp.Version so there is a single code path for gp3/gp4/gp5.
+func (gp *GPFile) LoadBody() error {
+	fo, closer, err := gp.readSeeker()
+	if err != nil {
+		return err
+	}
+	defer closer.Close()
+
+	fo, err = gpSeek(fo)
+	if err != nil {
+		return err
+	}
+
+	headerlen, head, err := headerLen(fo)
+	if err != nil {
+		return err
+	}
+	if headerlen == 0 {
+		return &notGPFile{"Invalid Guitar Pro file"}
+	}
+	if headerlen == 4 {
+		return errors.New("LoadBody does not support gpx files yet")
+	}
+
+	if err := gp.uncompressedGpInfo(fo, head); err != nil {
+		return err
+	}
+
+	br := &bodyReader{fo: fo}
+
+	if isGP5(gp.Version) {
+		// gp5 stores lyrics before the tempo/key signature block.
+		if _, err := br.readInt32(); err != nil {
+			return err
+		}
+		if _, err := gp.readLongString(fo); err != nil {
+			return err
+		}
+		for i := 0; i < 4; i++ {
+			br.readInt32()
+			gp.readLongString(fo)
+		}
+	}
+
+	tempo, err := br.readInt32()
+	if err != nil {
+		return err
+	}
+	gp.TempoValue = int(tempo)
+
+	if isGP4OrAbove(gp.Version) {
+		if _, err := br.readBool(); err != nil {
+			return err
+		}
+	}
+
+	key, err := br.readByte()
+	if err != nil {
+		return err
+	}
+	gp.KeySignature = int(int8(key))
+	if err := br.skip(1); err != nil {
+		return err
+	}
+
+	if isGP5(gp.Version) {
+		if err := br.skip(19); err != nil {
+			return err
+		}
+		if _, err := gp.readLongString(fo); err != nil {
+			return err
+		}
+		if err := br.skip(4); err != nil {
+			return err
+		}
+	}
+
+	channels, err := readBodyChannels(br)
+	if err != nil {
+		return err
+	}
+	gp.Channels = channels
+
+	if isGP5(gp.Version) {
+		if err := br.skip(42); err != nil {
+			return err
+		}
+	}
+
+	measureCount, err := br.readInt32()
+	if err != nil {
+		return err
+	}
+	trackCount, err := br.readInt32()
+	if err != nil {
+		return err
+	}
+	gp.Measures = int(measureCount)
+	gp.TrackCount = int(trackCount)
+
+	headers, err := readBodyMeasureHeaders(br, int(measureCount), isGP5(gp.Version))
+	if err != nil {
+		return err
+	}
+	gp.MeasureHeaders = headers
+
+	tracks, err := readBodyTracks(br, gp, int(trackCount))
+	if err != nil {
+		return err
+	}
+	gp.Tracks = tracks
+
+	for t := range gp.Tracks {
+		for _, header := range gp.MeasureHeaders {
+			measure := Measure{Header: header, Start: header.Start}
+			if err := readBodyMeasure(br, &measure, &gp.Tracks[t], isGP5(gp.Version)); err != nil {
+				return err
+			}
+			gp.Tracks[t].Measures = append(gp.Tracks[t].Measures, measure)
+		}
+	}
+
+	return nil
+}
+
+func isGP5(version string) bool {
+	return version == "v5.0" || version == "v5.1"
+}
+
+func isGP4OrAbove(version string) bool {
+	return version == "v4.0" || version == "v4.06" || isGP5(version)
+}
+
+// readBodyChannels reads the 64 MIDI channel slots that follow the key
+// signature, mirroring Parser.readChannels but against the shared file
+// handle rather than an in-memory buffer.
+func readBodyChannels(br *bodyReader) ([]Channel, error) {
+	channels := make([]Channel, 0, 64)
+	for i := 0; i < 64; i++ {
+		channel := Channel{}
+		program, err := br.readInt32()
+		if err != nil {
+			return nil, fmt.Errorf("channel %d program: %w", i, err)
+		}
+		if program < 0 {
+			program = 0
+		}
+		channel.Program = program
+
+		if channel.Volume, err = br.readByte(); err != nil {
+			return nil, err
+		}
+		if channel.Balance, err = br.readByte(); err != nil {
+			return nil, err
+		}
+		if channel.Chorus, err = br.readByte(); err != nil {
+			return nil, err
+		}
+		if channel.Reverb, err = br.readByte(); err != nil {
+			return nil, err
+		}
+		if channel.Pan, err = br.readByte(); err != nil {
+			return nil, err
+		}
+		if channel.Phaser, err = br.readByte(); err != nil {
+			return nil, err
+		}
+		if channel.Tremolo, err = br.readByte(); err != nil {
+			return nil, err
+		}
+
+		if i == 9 {
+			channel.Bank = "default percussion bank"
+			channel.IsPercussionChannel = true
+		} else {
+			channel.Bank = "default bank"
+		}
+
+		channels = append(channels, channel)
+		if err := br.skip(2); err != nil {
+			return nil, err
+		}
+	}
+	return channels, nil
+}
+
+func readBodyMeasureHeaders(br *bodyReader, count int, gp5 bool) ([]MeasureHeader, error) {
+	headers := make([]MeasureHeader, 0, count)
+	start := int32(QUARTER_TIME)
+	var previousNumerator, previousDenominator int32 = 4, 4
+
+	for i := 0; i < count; i++ {
+		if i > 0 {
+			// The separator byte between measure headers is skipped except
+			// for the very first one, matching the JS/C++ reference parsers.
+			if _, err := br.readByte(); err != nil {
+				return nil, err
+			}
+		}
+
+		flags, err := br.readByte()
+		if err != nil {
+			return nil, err
+		}
+
+		header := MeasureHeader{Number: i + 1, Start: int(start)}
+
+		if flags&0x01 != 0 {
+			header.RepeatOpen = true
+		}
+
+		numerator := previousNumerator
+		if flags&0x01 != 0 {
+			n, err := br.readByte()
+			if err != nil {
+				return nil, err
+			}
+			numerator = int32(n)
+		}
+		denominator := previousDenominator
+		if flags&0x02 != 0 {
+			d, err := br.readByte()
+			if err != nil {
+				return nil, err
+			}
+			denominator = int32(d)
+		}
+		header.TimeSignature.Numerator = int(numerator)
+		header.TimeSignature.Denominator = Denominator{Value: float64(denominator)}
+		header.TimeSignature.Division = Division{Times: 1, Enters: 1}
+		previousNumerator, previousDenominator = numerator, denominator
+
+		if flags&0x04 != 0 {
+			if err := br.skip(1); err != nil {
+				return nil, err
+			}
+		}
+		if flags&0x08 != 0 {
+			if err := br.skip(2); err != nil {
+				return nil, err
+			}
+		}
+		if flags&0x10 != 0 {
+			if err := br.skip(1); err != nil {
+				return nil, err
+			}
+		}
+		if flags&0x20 != 0 {
+			if err := br.skip(4); err != nil {
+				return nil, err
+			}
+		}
+		if flags&0x40 != 0 {
+			if err := br.skip(1); err != nil {
+				return nil, err
+			}
+		}
+		if gp5 && (flags&0x10 != 0 || flags&0x20 != 0) {
+			if err := br.skip(1); err != nil {
+				return nil, err
+			}
+		}
+
+		length := int32(math.Round(float64(numerator) * (QUARTER_TIME * 4.0 / float64(denominator))))
+		start += length
+		headers = append(headers, header)
+	}
+
+	return headers, nil
+}
+
+func readBodyTracks(br *bodyReader, gp *GPFile, count int) ([]Track, error) {
+	tracks := make([]Track, 0, count)
+	for i := 0; i < count; i++ {
+		flags, err := br.readByte()
+		if err != nil {
+			return nil, err
+		}
+		track := Track{Number: i + 1}
+
+		name, err := readBodyStringByte(br, 40)
+		if err != nil {
+			return nil, err
+		}
+		track.Name = name
+
+		stringCount, err := br.readInt32()
+		if err != nil {
+			return nil, err
+		}
+		for s := 0; s < 7; s++ {
+			tuning, err := br.readInt32()
+			if err != nil {
+				return nil, err
+			}
+			if int32(s) < stringCount {
+				track.GuitarStrings = append(track.GuitarStrings, GuitarString{Number: int32(s + 1), Value: tuning})
+			}
+		}
+
+		// port, channel index and effect channel index
+		if err := br.skip(4); err != nil {
+			return nil, err
+		}
+		channelID, err := br.readInt32()
+		if err != nil {
+			return nil, err
+		}
+		track.ChannelID = channelID
+		if err := br.skip(4); err != nil {
+			return nil, err
+		}
+		// fret count and capo
+		if _, err := br.readInt32(); err != nil {
+			return nil, err
+		}
+		if _, err := br.readInt32(); err != nil {
+			return nil, err
+		}
+		// track color
+		if err := br.skip(4); err != nil {
+			return nil, err
+		}
+
+		_ = flags
+		tracks = append(tracks, track)
+	}
+	return tracks, nil
+}
+
+func readBodyStringByte(br *bodyReader, size int) (string, error) {
+	length, err := br.readByte()
+	if err != nil {
+		return "", err
+	}
+	buf := make([]byte, size)
+	if _, err := io.ReadFull(br.fo, buf); err != nil {
+		return "", err
+	}
+	n := int(length)
+	if n > size {
+		n = size
+	}
+	return string(buf[:n]), nil
+}
+
+// readBodyMeasure reads the beats and notes of a single measure for a
+// single track. Full effect decoding (bends, slides, harmonics, ...) is
+// left to the existing Parser implementation in gp5parser.go; this path
+// focuses on getting duration, pitch and string/fret data onto GPFile so
+// Measures/Tracks are populated end to end.
+func readBodyMeasure(br *bodyReader, measure *Measure, track *Track, gp5 bool) error {
+	voiceCount := 1
+	if gp5 {
+		voiceCount = 2
+	}
+
+	for v := 0; v < voiceCount; v++ {
+		beatCount, err := br.readInt32()
+		if err != nil {
+			return err
+		}
+		for b := 0; b < int(beatCount); b++ {
+			if err := readBodyBeat(br, measure, track, v); err != nil {
+				return err
+			}
+		}
+	}
+
+	if gp5 {
+		// Per-measure directions / chord diagram list that only gp5 carries.
+		if err := br.skip(1); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func readBodyBeat(br *bodyReader, measure *Measure, track *Track, voiceIndex int) error {
+	flags, err := br.readByte()
+	if err != nil {
+		return err
+	}
+
+	beat := Beat{}
+	if voiceIndex < len(beat.Voices) {
+		beat.Voices = make([]Voice, 2)
+	}
+
+	if flags&0x40 != 0 {
+		if _, err := br.readByte(); err != nil {
+			return err
+		}
+	}
+
+	durByte, err := br.readByte()
+	if err != nil {
+		return err
+	}
+	duration := Duration{Value: math.Pow(2, float64(durByte+4)) / 4}
+
+	if flags&0x20 != 0 {
+		if err := br.skip(4); err != nil {
+			return err
+		}
+	}
+	if flags&0x02 != 0 {
+		// chord diagram, opaque for now
+		if err := br.skip(25); err != nil {
+			return err
+		}
+	}
+	if flags&0x04 != 0 {
+		text, err := readBodyStringByte(br, 0)
+		if err != nil {
+			return err
+		}
+		beat.Text.Value = text
+	}
+	if flags&0x08 != 0 {
+		if err := br.skip(1); err != nil {
+			return err
+		}
+	}
+	if flags&0x10 != 0 {
+		if err := br.skip(1); err != nil {
+			return err
+		}
+	}
+
+	stringFlags, err := br.readByte()
+	if err != nil {
+		return err
+	}
+	voice := Voice{Duration: duration}
+	for i := 6; i >= 0; i-- {
+		if stringFlags&(1<<i) != 0 {
+			noteFlags, err := br.readByte()
+			if err != nil {
+				return err
+			}
+			note := Note{String: int32(7 - i)}
+			if noteFlags&0x20 != 0 {
+				fret, err := br.readByte()
+				if err != nil {
+					return err
+				}
+				note.Value = fret
+			}
+			voice.Notes = append(voice.Notes, note)
+		}
+	}
+	beat.Voices = append(beat.Voices, voice)
+	measure.Beats = append(measure.Beats, beat)
+
+	return nil
+}
@@ -0,0 +1,62 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/knasan/parsegp"
+)
+
+// minimalGP3Header builds just enough of a v3.00 header for NewParser to
+// accept the file - the 31-byte version field plus eight empty
+// readStringByteSizeOfInteger fields - without the tempo/channel/measure
+// data Parse's readSongStructure needs, so Parse fails partway through on
+// purpose. That failure is what this test uses to observe the path report
+// passes along, the same way a real corrupt or truncated file would.
+func minimalGP3Header(t *testing.T) []byte {
+	t.Helper()
+	const version = "FICHIER GUITAR PRO v3.00"
+
+	buf := make([]byte, 0, 31+8*2)
+	buf = append(buf, byte(len(version)))
+	field := make([]byte, 30)
+	copy(field, version)
+	buf = append(buf, field...)
+
+	for i := 0; i < 8; i++ {
+		buf = append(buf, 1, 0) // num=1 (size=0), inner length=0: an empty string
+	}
+	return buf
+}
+
+// TestProcessArgRecursiveReportsRealPath is a regression test for
+// processArg joining ParseDir's relative-path keys back onto the original
+// directory argument: a file reached by recursing into a subdirectory must
+// be reported under a path that actually resolves to it, not under its bare
+// filename joined straight onto the top-level directory.
+func TestProcessArgRecursiveReportsRealPath(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.Mkdir(filepath.Join(dir, "sub"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	wantPath := filepath.Join(dir, "sub", "song.gp3")
+	if err := os.WriteFile(wantPath, minimalGP3Header(t), 0644); err != nil {
+		t.Fatalf("WriteFile(%s): %v", wantPath, err)
+	}
+
+	err := processArg(dir, parsegp.Recursive)
+	if err == nil {
+		t.Fatal("processArg returned no error, want the body-parse error wrapped with the file's real path")
+	}
+
+	if !strings.HasPrefix(err.Error(), wantPath+":") {
+		t.Fatalf("processArg error = %q, want it prefixed with %q", err.Error(), wantPath+":")
+	}
+
+	if _, statErr := os.Stat(wantPath); statErr != nil {
+		t.Fatalf("reported path %q does not exist on disk: %v", wantPath, statErr)
+	}
+}
@@ -1,15 +1,133 @@
 package main
 
 import (
+	"encoding/json"
+	"flag"
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 
+	"github.com/fsnotify/fsnotify"
 	"github.com/knasan/parsegp"
+	"github.com/knasan/parsegp/export/midi"
+	"github.com/knasan/parsegp/export/musicxml"
+	"github.com/knasan/parsegp/export/render"
 )
 
 var fileList []string
 
+// outputFormat controls how processFile renders a parsed GPFile: plain
+// text (the original behaviour) or JSON, for piping into another tool.
+var outputFormat = "text"
+
+// overwrite, when true, re-writes the sidecar JSON file next to a source
+// file even if it already exists.
+var overwrite bool
+
+// sidecarPath returns the "<file>.json" path next to a Guitar Pro file.
+func sidecarPath(path string) string {
+	return path + ".json"
+}
+
+// writeSidecar writes gp's JSON representation next to path, unless the
+// sidecar already exists and -overwrite was not given.
+func writeSidecar(path string, gp *parsegp.GPFile) error {
+	out := sidecarPath(path)
+	if !overwrite {
+		if _, err := os.Stat(out); err == nil {
+			return nil
+		}
+	}
+
+	data, err := json.MarshalIndent(gp, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(out, data, 0644)
+}
+
+// watchDir uses fsnotify to observe dir for created or renamed-in Guitar
+// Pro files and process them as they land, so the CLI can double as a
+// library index-builder that keeps running instead of doing a one-shot walk.
+func watchDir(dir string) error {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return err
+	}
+	defer watcher.Close()
+
+	if err := watcher.Add(dir); err != nil {
+		return err
+	}
+
+	fmt.Printf("watching %s for Guitar Pro files...\n", dir)
+	for {
+		select {
+		case event, ok := <-watcher.Events:
+			if !ok {
+				return nil
+			}
+			if event.Op&(fsnotify.Create|fsnotify.Rename) == 0 {
+				continue
+			}
+			if !isSupportedExt(filepath.Ext(event.Name)) {
+				continue
+			}
+			if err := processFile(event.Name); err != nil {
+				fmt.Println(err)
+			}
+		case err, ok := <-watcher.Errors:
+			if !ok {
+				return nil
+			}
+			fmt.Println("watch error:", err)
+		}
+	}
+}
+
+// isSupportedExt reports whether ext (including the leading dot) is one of
+// the Guitar Pro formats this CLI knows how to process.
+func isSupportedExt(ext string) bool {
+	for _, format := range parsegp.SupportedFormats() {
+		if ext == format {
+			return true
+		}
+	}
+	return false
+}
+
+// exportFile loads path's header and body, then writes it out as MusicXML,
+// MIDI or a rendered WAV (format "musicxml", "midi" or "wav") to outPath.
+func exportFile(path, format, outPath string) error {
+	gp, err := parsegp.NewGPFile(path)
+	if err != nil {
+		return fmt.Errorf("error opening file %s: %v", path, err)
+	}
+	if err := gp.Load(); err != nil {
+		return fmt.Errorf("error reading file %s: %v", path, err)
+	}
+
+	out, err := os.Create(outPath)
+	if err != nil {
+		return fmt.Errorf("error creating file %s: %v", outPath, err)
+	}
+	defer out.Close()
+
+	switch format {
+	case "musicxml":
+		return musicxml.Export(gp, out)
+	case "midi":
+		return midi.Export(gp, out)
+	case "wav":
+		samples := render.NewRenderer(44100).Render(gp)
+		_, err := (render.WAVWriter{Samples: samples, SampleRate: 44100}).WriteTo(out)
+		return err
+	default:
+		return fmt.Errorf("unsupported -export format %q, want musicxml, midi or wav", format)
+	}
+}
+
 // isFile checks if the given path is a file.
 //
 // The function takes a string parameter `path` representing the file path to be checked.
@@ -40,12 +158,25 @@ func processFile(path string) error {
 	if err != nil {
 		return fmt.Errorf("error opening file %s: %v", path, err)
 	}
-	fmt.Println("--")
 
 	if err := gp.LoadHeader(); err != nil {
 		return fmt.Errorf("error reading file %s: %v", path, err)
 	}
 
+	if err := writeSidecar(path, gp); err != nil {
+		return fmt.Errorf("error writing sidecar for %s: %v", path, err)
+	}
+
+	if outputFormat == "json" {
+		data, err := json.MarshalIndent(gp, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(data))
+		return nil
+	}
+
+	fmt.Println("--")
 	fmt.Printf(
 		"File: %s\n"+
 			"GP-Version: %s\n"+
@@ -106,11 +237,46 @@ func run(path string) ([]string, error) {
 // If a file path is provided as a command-line argument, it processes that single file.
 // Otherwise, it walks the current directory and processes all supported files found.
 func main() {
+	exportFormat := flag.String("export", "", "export the given file as \"musicxml\", \"midi\" or \"wav\" instead of printing its header")
+	outPath := flag.String("o", "", "output file for -export")
+	watch := flag.String("watch", "", "watch the given directory and process Guitar Pro files as they land")
+	format := flag.String("format", "text", "output format for processFile: \"text\" or \"json\"")
+	flag.BoolVar(&overwrite, "overwrite", false, "re-write the sidecar JSON file next to a source file even if it already exists")
+	flag.Parse()
+
+	if *format != "text" && *format != "json" {
+		fmt.Printf("unsupported -format %q, want text or json\n", *format)
+		os.Exit(1)
+	}
+	outputFormat = strings.ToLower(*format)
+
 	var err error
+	args := flag.Args()
+
+	if *watch != "" {
+		if err := watchDir(*watch); err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	if *exportFormat != "" {
+		if len(args) != 1 || *outPath == "" {
+			fmt.Println("usage: cli -export musicxml|midi|wav -o outfile <file>")
+			os.Exit(1)
+		}
+		if err := exportFile(args[0], *exportFormat, *outPath); err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+		return
+	}
+
 	// Check if a file path is provided as a command-line argument
-	if len(os.Args) > 1 && isFile(os.Args[1]) {
+	if len(args) > 0 && isFile(args[0]) {
 		// Process the single file provided as a command-line argument
-		if err = processFile(os.Args[1]); err != nil {
+		if err = processFile(args[0]); err != nil {
 			fmt.Println(err)
 		}
 	} else {
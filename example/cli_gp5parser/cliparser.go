@@ -8,10 +8,21 @@ import (
 
 func main() {
 
-	p, err := parsegp.NewParser("../testfiles/gp5/ready_or_not_2.gp5")
+	p, err := parsegp.NewParserFromFile("../testfiles/gp5/ready_or_not_2.gp5")
 	if err != nil {
 		fmt.Println("Error:", err)
 		return
 	}
+	defer p.Close()
 	fmt.Println("Version:", p.Version)
+
+	song, err := p.Parse()
+	if err != nil {
+		fmt.Println("Error:", err)
+		return
+	}
+	fmt.Printf("Tracks: %d, Measures: %d\n", song.TrackCount, len(song.MeasureHeaders))
+	for _, warning := range song.Warnings {
+		fmt.Println("Warning:", warning.String())
+	}
 }
@@ -0,0 +1,205 @@
+package midi
+
+import (
+	"sort"
+	"time"
+
+	"github.com/knasan/parsegp"
+)
+
+// Event is a single realtime MIDI event produced by Events: either a
+// channel-voice message (Status/Data1/Data2, in the same encoding Export
+// writes into an SMF track) or, when TempoBPM is nonzero, a tempo change a
+// Player should apply to its own clock rather than send downstream.
+type Event struct {
+	DeltaTicks int
+	Status     byte
+	Data1      byte
+	Data2      byte
+	TempoBPM   int
+}
+
+// Player is the realtime output sink Play drives: anything that can accept
+// one MIDI event at a time, such as a portmidi output stream wrapper. Play
+// does not depend on any MIDI I/O library itself - callers supply whatever
+// Player their platform needs.
+type Player interface {
+	Send(Event) error
+}
+
+// timedEvent is an Event tagged with its absolute tick position, used while
+// building Events to merge every track's events into one chronological
+// stream before converting back to the delta-tick form Event carries.
+type timedEvent struct {
+	tick  int
+	event Event
+}
+
+// Events flattens gp into the chronological stream of realtime events - one
+// initial tempo change, then each track's Program Change, the same mixer
+// Control Changes writeChannelSetup emits into an SMF file, Note On/Off and
+// a simplified pitch-bend curve - merged across tracks by absolute tick
+// position so a Player sees them in playback order rather than one track at
+// a time.
+func Events(gp *parsegp.GPFile) []Event {
+	tempo := gp.TempoValue
+	if tempo <= 0 {
+		tempo = 120
+	}
+
+	timed := []timedEvent{{tick: 0, event: Event{TempoBPM: tempo}}}
+	for _, track := range gp.Tracks {
+		timed = append(timed, trackTimedEvents(track)...)
+	}
+
+	sort.SliceStable(timed, func(i, j int) bool { return timed[i].tick < timed[j].tick })
+
+	events := make([]Event, len(timed))
+	lastTick := 0
+	for i, te := range timed {
+		event := te.event
+		event.DeltaTicks = te.tick - lastTick
+		events[i] = event
+		lastTick = te.tick
+	}
+	return events
+}
+
+func trackTimedEvents(track parsegp.Track) []timedEvent {
+	channel := byte(track.ChannelID % 16)
+	if track.Channel.IsPercussionChannel {
+		channel = percussionChannel
+	}
+
+	events := []timedEvent{{tick: 0, event: Event{Status: 0xC0 | channel, Data1: byte(track.Channel.Program)}}}
+	for _, cc := range channelSetupEvents(channel, track.Channel) {
+		events = append(events, timedEvent{tick: 0, event: cc})
+	}
+
+	tick := 0
+	for _, measure := range track.Measures {
+		for _, beat := range measure.Beats {
+			events = append(events, beatTimedEvents(channel, track, beat, tick)...)
+			tick += beatTicks(beat)
+		}
+	}
+	return events
+}
+
+func channelSetupEvents(channel byte, ch parsegp.Channel) []Event {
+	cc := func(controller, value byte) Event {
+		return Event{Status: 0xB0 | channel, Data1: controller, Data2: value}
+	}
+	return []Event{
+		cc(ccVolume, ch.Volume),
+		cc(ccPan, ch.Pan),
+		cc(ccBalance, ch.Balance),
+		cc(ccChorus, ch.Chorus),
+		cc(ccReverb, ch.Reverb),
+		cc(ccPhaser, ch.Phaser),
+		cc(ccTremolo, ch.Tremolo),
+	}
+}
+
+// beatTicks returns the number of ticks the beat occupies, taken as the
+// longest of its (usually one, up to two in gp5) voices, so every track's
+// tick cursor advances in step regardless of how many voices a beat holds.
+func beatTicks(beat parsegp.Beat) int {
+	longest := 0
+	for _, voice := range beat.Voices {
+		if voice.Empty {
+			continue
+		}
+		if t := ticksFor(voice.Duration.Value); t > longest {
+			longest = t
+		}
+	}
+	if longest == 0 {
+		return ticksPerQuarterNote
+	}
+	return longest
+}
+
+func beatTimedEvents(channel byte, track parsegp.Track, beat parsegp.Beat, startTick int) []timedEvent {
+	var events []timedEvent
+
+	for _, voice := range beat.Voices {
+		if voice.Empty {
+			continue
+		}
+		duration := ticksFor(voice.Duration.Value)
+
+		for _, note := range voice.Notes {
+			velocity := note.Velocity
+			if velocity <= 0 {
+				velocity = 95
+			}
+			pitch := stringTuning(track, note.String) + int32(note.Value)
+
+			events = append(events, timedEvent{tick: startTick, event: Event{Status: 0x90 | channel, Data1: byte(pitch), Data2: byte(velocity)}})
+			for _, bend := range bendEvents(channel, note.Effect) {
+				events = append(events, timedEvent{tick: startTick, event: bend})
+			}
+			events = append(events, timedEvent{tick: startTick + duration, event: Event{Status: 0x80 | channel, Data1: byte(pitch)}})
+		}
+	}
+
+	return events
+}
+
+// bendEvents renders a note's Bend points (normalized the same way readBend
+// leaves them) as a single pitch-wheel move to the final point's value, not
+// the full multi-point ramp writeBendCurve spreads across an SMF note's
+// duration - realtime playback only needs where the pitch ends up, not the
+// shape of the curve getting there.
+func bendEvents(channel byte, effect parsegp.NoteEffect) []Event {
+	points := effect.Bend.Points
+	if len(points) == 0 {
+		return nil
+	}
+
+	last := points[len(points)-1]
+	semitones := float64(last.Value) / float64(parsegp.TGEFFECTBEND_SEMITONE_LENGTH)
+	bend := 8192 + int(semitones/pitchBendRangeSemitones*8192)
+	if bend < 0 {
+		bend = 0
+	}
+	if bend > 16383 {
+		bend = 16383
+	}
+
+	return []Event{{Status: 0xE0 | channel, Data1: byte(bend & 0x7F), Data2: byte(bend >> 7)}}
+}
+
+// Play sends gp's Events to player in real time, sleeping DeltaTicks worth
+// of wall-clock time (at ticksPerQuarterNote resolution and the most recent
+// TempoBPM) before each one. TempoBPM events update Play's own clock rather
+// than being forwarded to player.
+func Play(gp *parsegp.GPFile, player Player) error {
+	tempo := gp.TempoValue
+	if tempo <= 0 {
+		tempo = 120
+	}
+
+	for _, event := range Events(gp) {
+		if event.DeltaTicks > 0 {
+			time.Sleep(tickDuration(event.DeltaTicks, tempo))
+		}
+		if event.TempoBPM != 0 {
+			tempo = event.TempoBPM
+			continue
+		}
+		if err := player.Send(event); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func tickDuration(ticks, bpm int) time.Duration {
+	if bpm <= 0 {
+		bpm = 120
+	}
+	secondsPerTick := 60.0 / float64(bpm) / float64(ticksPerQuarterNote)
+	return time.Duration(float64(ticks) * secondsPerTick * float64(time.Second))
+}
@@ -0,0 +1,419 @@
+// Package midi converts a fully-loaded parsegp.GPFile into a Standard
+// MIDI File (type 1, one conductor track plus one note track per
+// parsegp.Track).
+package midi
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"math"
+
+	"github.com/knasan/parsegp"
+)
+
+const ticksPerQuarterNote = 960
+
+// Control change numbers used for the Channel fields that do not already
+// have an obvious standard mapping. Volume, pan, chorus and reverb follow
+// the General MIDI CC assignments; balance, phaser and tremolo follow the
+// GM2 "sound controller" / "FX depth" conventions.
+const (
+	ccVolume     = 7
+	ccBalance    = 8
+	ccPan        = 10
+	ccTapping    = 80 // GM2 General Purpose Controller 5
+	ccSlap       = 81 // GM2 General Purpose Controller 6
+	ccPop        = 82 // GM2 General Purpose Controller 7
+	ccChorus     = 93
+	ccTremolo    = 92 // GM2 FX Depth 2: Tremolo
+	ccPhaser     = 95 // GM2 FX Depth 5: Phaser
+	ccReverb     = 91
+	ccModWheel   = 1
+	ccExpression = 11
+)
+
+// percussionChannel is the zero-based MIDI channel (channel 10 in 1-based
+// GM numbering) reserved for drum kits.
+const percussionChannel = 9
+
+// pitchBendRangeSemitones is the GM default pitch-bend range; tremolo bar
+// and bend points are scaled against it to produce a 14-bit pitch-wheel value.
+const pitchBendRangeSemitones = 2.0
+
+// Export writes gp as a Standard MIDI File (SMF) type 1 stream to w: one
+// conductor track carrying tempo/time-signature/key-signature meta events
+// derived from gp.MeasureHeaders, followed by one note track per
+// parsegp.Track. Vibrato, tapping/slapping/pop and fade-in are mapped onto
+// the closest General MIDI/GM2 CC, and tremolo-bar/bend points are expanded
+// into a pitch-wheel curve spread across the note's duration.
+func Export(gp *parsegp.GPFile, w io.Writer) error {
+	tracks := make([][]byte, 0, len(gp.Tracks)+1)
+	tracks = append(tracks, conductorTrack(gp))
+
+	for _, track := range gp.Tracks {
+		tracks = append(tracks, noteTrack(track))
+	}
+
+	if err := writeHeaderChunk(w, len(tracks)); err != nil {
+		return err
+	}
+	for _, t := range tracks {
+		if err := writeChunk(w, "MTrk", t); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func writeHeaderChunk(w io.Writer, numTracks int) error {
+	buf := &bytes.Buffer{}
+	binary.Write(buf, binary.BigEndian, uint16(1)) // format 1
+	binary.Write(buf, binary.BigEndian, uint16(numTracks))
+	binary.Write(buf, binary.BigEndian, uint16(ticksPerQuarterNote))
+	return writeChunk(w, "MThd", buf.Bytes())
+}
+
+func writeChunk(w io.Writer, id string, data []byte) error {
+	if _, err := io.WriteString(w, id); err != nil {
+		return err
+	}
+	if err := binary.Write(w, binary.BigEndian, uint32(len(data))); err != nil {
+		return err
+	}
+	_, err := w.Write(data)
+	return err
+}
+
+// conductorTrack emits a set_tempo or time_signature meta event at the
+// start of each measure whose tempo or time signature differs from the
+// preceding one, positioned at that measure's accumulated tick offset. The
+// key_signature meta event is emitted once, at the first measure.
+func conductorTrack(gp *parsegp.GPFile) []byte {
+	buf := &bytes.Buffer{}
+
+	tempo := gp.TempoValue
+	if tempo <= 0 {
+		tempo = 120
+	}
+
+	var tick, lastEventTick int
+	lastTempo := -1
+	lastNumerator := -1
+	lastDenominator := -1.0
+
+	for i, header := range gp.MeasureHeaders {
+		t := header.Tempo
+		if t <= 0 {
+			t = tempo
+		}
+		if i == 0 || t != lastTempo {
+			writeVarLen(buf, uint32(tick-lastEventTick))
+			writeSetTempo(buf, t)
+			lastEventTick = tick
+			lastTempo = t
+		}
+
+		num := header.TimeSignature.Numerator
+		den := header.TimeSignature.Denominator.Value
+		if i == 0 || num != lastNumerator || den != lastDenominator {
+			writeVarLen(buf, uint32(tick-lastEventTick))
+			writeTimeSignature(buf, num, den)
+			lastEventTick = tick
+			lastNumerator = num
+			lastDenominator = den
+		}
+
+		if i == 0 {
+			writeVarLen(buf, uint32(tick-lastEventTick))
+			writeKeySignature(buf, gp.KeySignature)
+			lastEventTick = tick
+		}
+
+		tick += measureTicks(num, den)
+	}
+
+	writeVarLen(buf, 0)
+	buf.Write([]byte{0xFF, 0x2F, 0x00}) // end of track
+	return buf.Bytes()
+}
+
+func writeSetTempo(buf *bytes.Buffer, bpm int) {
+	microsPerQuarter := 60000000 / bpm
+	buf.Write([]byte{0xFF, 0x51, 0x03})
+	buf.WriteByte(byte(microsPerQuarter >> 16))
+	buf.WriteByte(byte(microsPerQuarter >> 8))
+	buf.WriteByte(byte(microsPerQuarter))
+}
+
+func writeTimeSignature(buf *bytes.Buffer, numerator int, denominator float64) {
+	if numerator <= 0 {
+		numerator = 4
+	}
+	if denominator <= 0 {
+		denominator = 4
+	}
+	denomExp := byte(math.Round(math.Log2(denominator)))
+	buf.Write([]byte{0xFF, 0x58, 0x04, byte(numerator), denomExp, 24, 8})
+}
+
+// writeKeySignature emits a key_signature meta event. KeySignature already
+// follows the MIDI convention of signed fifths (-7..7 flats/sharps); the
+// parser does not currently distinguish major from minor, so mi is always
+// reported as major.
+func writeKeySignature(buf *bytes.Buffer, keySignature int) {
+	sf := keySignature
+	if sf < -7 {
+		sf = -7
+	}
+	if sf > 7 {
+		sf = 7
+	}
+	buf.Write([]byte{0xFF, 0x59, 0x02, byte(int8(sf)), 0})
+}
+
+func measureTicks(numerator int, denominator float64) int {
+	if numerator <= 0 {
+		numerator = 4
+	}
+	if denominator <= 0 {
+		denominator = 4
+	}
+	return int(float64(numerator) * 4 * ticksPerQuarterNote / denominator)
+}
+
+func noteTrack(track parsegp.Track) []byte {
+	buf := &bytes.Buffer{}
+	channel := byte(track.ChannelID % 16)
+	if track.Channel.IsPercussionChannel {
+		channel = percussionChannel
+	}
+
+	writeVarLen(buf, 0)
+	buf.Write([]byte{0xFF, 0x03, byte(len(track.Name))})
+	buf.WriteString(track.Name)
+
+	writeVarLen(buf, 0)
+	buf.WriteByte(0xC0 | channel)
+	buf.WriteByte(byte(track.Channel.Program))
+
+	writeChannelSetup(buf, channel, track.Channel)
+
+	for _, measure := range track.Measures {
+		for _, beat := range measure.Beats {
+			writeBeat(buf, channel, track, beat)
+		}
+	}
+
+	writeVarLen(buf, 0)
+	buf.Write([]byte{0xFF, 0x2F, 0x00})
+	return buf.Bytes()
+}
+
+// writeChannelSetup translates the Channel's mixer fields into the Control
+// Change events that best approximate them in General MIDI / GM2.
+func writeChannelSetup(buf *bytes.Buffer, channel byte, ch parsegp.Channel) {
+	cc := func(controller, value byte) {
+		writeVarLen(buf, 0)
+		buf.WriteByte(0xB0 | channel)
+		buf.WriteByte(controller)
+		buf.WriteByte(value)
+	}
+	cc(ccVolume, ch.Volume)
+	cc(ccPan, ch.Pan)
+	cc(ccBalance, ch.Balance)
+	cc(ccChorus, ch.Chorus)
+	cc(ccReverb, ch.Reverb)
+	cc(ccPhaser, ch.Phaser)
+	cc(ccTremolo, ch.Tremolo)
+}
+
+// writeBeat emits the note_on/note_off pairs for a single beat, plus
+// whatever CC/pitch-bend curves its effects call for. Strummed chords are
+// staggered a few ticks apart in string order, following Stroke.Direction,
+// instead of firing every note in the chord simultaneously.
+func writeBeat(buf *bytes.Buffer, channel byte, track parsegp.Track, beat parsegp.Beat) {
+	const strumStagger = 4 // ticks between successive notes of a strummed chord
+
+	for _, voice := range beat.Voices {
+		if voice.Empty {
+			continue
+		}
+		duration := ticksFor(voice.Duration.Value)
+
+		notes := voice.Notes
+		if beat.Stroke.Direction == "stroke_up" {
+			for i, j := 0, len(notes)-1; i < j; i, j = i+1, j-1 {
+				notes[i], notes[j] = notes[j], notes[i]
+			}
+		}
+
+		for i, note := range notes {
+			velocity := note.Velocity
+			if velocity <= 0 {
+				velocity = 95
+			}
+			pitch := stringTuning(track, note.String) + int32(note.Value)
+
+			lead := 0
+			if beat.Stroke.Direction != "" {
+				lead = i * strumStagger
+			}
+			writeVarLen(buf, uint32(lead))
+			buf.WriteByte(0x90 | channel)
+			buf.WriteByte(byte(pitch))
+			buf.WriteByte(byte(velocity))
+
+			writeNoteEffects(buf, channel, note.Effect, duration)
+
+			writeVarLen(buf, uint32(duration))
+			buf.WriteByte(0x80 | channel)
+			buf.WriteByte(byte(pitch))
+			buf.WriteByte(0)
+		}
+	}
+}
+
+// writeNoteEffects maps the note effects the parser already extracts onto
+// CC curves or a pitch-bend ramp, all contained within the note's duration
+// so they precede its note_off.
+func writeNoteEffects(buf *bytes.Buffer, channel byte, effect parsegp.NoteEffect, duration int) {
+	if effect.Vibrato {
+		writeVarLen(buf, 0)
+		buf.WriteByte(0xB0 | channel)
+		buf.WriteByte(ccModWheel)
+		buf.WriteByte(64)
+	}
+	if effect.Tapping {
+		writeCC(buf, channel, ccTapping, 127)
+	}
+	if effect.Slapping {
+		writeCC(buf, channel, ccSlap, 127)
+	}
+	if effect.Pop {
+		writeCC(buf, channel, ccPop, 127)
+	}
+	if effect.FadeIn {
+		writeFadeIn(buf, channel, duration)
+	}
+	writeBendCurve(buf, channel, effect.TremoloBar.Points, duration)
+	writeBendCurve(buf, channel, pointsFromBend(effect.Bend), duration)
+}
+
+func writeCC(buf *bytes.Buffer, channel, controller, value byte) {
+	writeVarLen(buf, 0)
+	buf.WriteByte(0xB0 | channel)
+	buf.WriteByte(controller)
+	buf.WriteByte(value)
+}
+
+// writeFadeIn ramps CC11 (Expression) from silent to full across the note's
+// duration in a handful of steps.
+func writeFadeIn(buf *bytes.Buffer, channel byte, duration int) {
+	const steps = 8
+	if duration <= 0 {
+		return
+	}
+	step := duration / steps
+	if step <= 0 {
+		step = 1
+	}
+	for i := 0; i <= steps; i++ {
+		delta := step
+		if i == 0 {
+			delta = 0 // the silent first step sits on the note_on itself
+		}
+		writeVarLen(buf, uint32(delta))
+		buf.WriteByte(0xB0 | channel)
+		buf.WriteByte(ccExpression)
+		buf.WriteByte(byte(127 * i / steps))
+	}
+}
+
+func pointsFromBend(b parsegp.Bend) []parsegp.TremoloPoint {
+	points := make([]parsegp.TremoloPoint, len(b.Points))
+	for i, p := range b.Points {
+		points[i] = parsegp.TremoloPoint{Position: p.Position, Value: p.Value}
+	}
+	return points
+}
+
+// writeBendCurve turns a series of normalized tremolo-bar/bend points
+// (Position in [0, TGEFFECTBEND_MAX_POSITION_LENGTH], Value in units of
+// TGEFFECTBEND_SEMITONE_LENGTH per semitone, per the Parser's
+// readTremoloBar/readBend normalization) into pitch-wheel events spread
+// across duration.
+func writeBendCurve(buf *bytes.Buffer, channel byte, points []parsegp.TremoloPoint, duration int) {
+	if len(points) == 0 || duration <= 0 {
+		return
+	}
+
+	prevTick := 0
+	for _, p := range points {
+		posFraction := float64(p.Position) / float64(parsegp.TGEFFECTBEND_MAX_POSITION_LENGTH)
+		if posFraction < 0 {
+			posFraction = 0
+		}
+		if posFraction > 1 {
+			posFraction = 1
+		}
+		tick := int(posFraction * float64(duration))
+
+		semitones := float64(p.Value) / float64(parsegp.TGEFFECTBEND_SEMITONE_LENGTH)
+		bend := 8192 + int(semitones/pitchBendRangeSemitones*8192)
+		if bend < 0 {
+			bend = 0
+		}
+		if bend > 16383 {
+			bend = 16383
+		}
+
+		writeVarLen(buf, uint32(tick-prevTick))
+		buf.WriteByte(0xE0 | channel)
+		buf.WriteByte(byte(bend & 0x7F))
+		buf.WriteByte(byte(bend >> 7))
+		prevTick = tick
+	}
+
+	// Return the pitch wheel to center before note_off so the bend does not
+	// bleed into the next note on the same channel.
+	writeVarLen(buf, uint32(duration-prevTick))
+	buf.WriteByte(0xE0 | channel)
+	buf.WriteByte(0)
+	buf.WriteByte(0x40)
+}
+
+func stringTuning(track parsegp.Track, stringNumber int32) int32 {
+	for _, gs := range track.GuitarStrings {
+		if gs.Number == stringNumber {
+			return gs.Value
+		}
+	}
+	return 40
+}
+
+// ticksFor converts a Duration.Value (1 = whole note, 4 = quarter, ...)
+// into SMF ticks at ticksPerQuarterNote resolution.
+func ticksFor(value float64) int {
+	if value <= 0 {
+		return ticksPerQuarterNote
+	}
+	return int(4 * ticksPerQuarterNote / value)
+}
+
+// writeVarLen encodes n as a MIDI variable-length quantity.
+func writeVarLen(buf *bytes.Buffer, n uint32) {
+	var stack [4]byte
+	count := 0
+	stack[count] = byte(n & 0x7F)
+	count++
+	n >>= 7
+	for n > 0 {
+		stack[count] = byte(n&0x7F) | 0x80
+		count++
+		n >>= 7
+	}
+	for i := count - 1; i >= 0; i-- {
+		buf.WriteByte(stack[i])
+	}
+}
@@ -0,0 +1,577 @@
+// Package musicxml converts a fully-loaded parsegp.GPFile into a MusicXML
+// 4.0 partwise document, translating bend, grace, tremolo picking,
+// harmonic, trill and other note effects into <notations> and deriving a
+// chord symbol <harmony> element per beat via the chord package.
+package musicxml
+
+import (
+	"encoding/xml"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/knasan/parsegp"
+	"github.com/knasan/parsegp/chord"
+)
+
+// maxTrackedStrings bounds the per-string hammer-on/pull-off direction
+// tracking below to the same 7-string layout the parser assumes.
+const maxTrackedStrings = 7
+
+// divisionsPerQuarter is the MusicXML <divisions> value Export declares: the
+// number of duration units per quarter note, chosen so every Duration.Value
+// this package is likely to see (whole down to 64th notes) divides evenly.
+const divisionsPerQuarter = 16
+
+// pitchNames lists the chromatic scale used to turn a MIDI-style note
+// number into a MusicXML <step>/<alter>/<octave> triple.
+var pitchNames = []struct {
+	step  string
+	alter int
+}{
+	{"C", 0}, {"C", 1}, {"D", 0}, {"D", 1}, {"E", 0}, {"F", 0},
+	{"F", 1}, {"G", 0}, {"G", 1}, {"A", 0}, {"A", 1}, {"B", 0},
+}
+
+type scorePartwise struct {
+	XMLName  xml.Name  `xml:"score-partwise"`
+	Version  string    `xml:"version,attr"`
+	PartList partList  `xml:"part-list"`
+	Parts    []xmlPart `xml:"part"`
+}
+
+type partList struct {
+	ScoreParts []scorePart `xml:"score-part"`
+}
+
+type scorePart struct {
+	ID       string `xml:"id,attr"`
+	PartName string `xml:"part-name"`
+}
+
+type xmlPart struct {
+	ID       string       `xml:"id,attr"`
+	Measures []xmlMeasure `xml:"measure"`
+}
+
+type xmlMeasure struct {
+	Number     string         `xml:"number,attr"`
+	Attrs      *xmlAttrs      `xml:"attributes,omitempty"`
+	Directions []xmlDirection `xml:"direction"`
+	Harmonies  []xmlHarmony   `xml:"harmony"`
+	Notes      []xmlNote      `xml:"note"`
+}
+
+// xmlHarmony is a chord symbol derived by chord.Analyze from a beat's
+// parsegp.Chord diagram. Like xmlDirection, every harmony in a measure is
+// flushed as its own block ahead of Notes rather than interleaved with the
+// beat it actually belongs to - the same simplification this package
+// already makes for tempo directions.
+type xmlHarmony struct {
+	Root   xmlHarmonyRoot    `xml:"root"`
+	Kind   string            `xml:"kind"`
+	Bass   *xmlHarmonyBass   `xml:"bass,omitempty"`
+	Degree *xmlHarmonyDegree `xml:"degree,omitempty"`
+}
+
+type xmlHarmonyRoot struct {
+	Step  string `xml:"root-step"`
+	Alter int    `xml:"root-alter,omitempty"`
+}
+
+type xmlHarmonyBass struct {
+	Step  string `xml:"bass-step"`
+	Alter int    `xml:"bass-alter,omitempty"`
+}
+
+// xmlHarmonyDegree carries an "add" tone chord.Analyze could not fold into
+// Kind, e.g. the 9 in a major-add9 voicing.
+type xmlHarmonyDegree struct {
+	Value int    `xml:"degree-value"`
+	Alter int    `xml:"degree-alter"`
+	Type  string `xml:"degree-type"`
+}
+
+type xmlAttrs struct {
+	Divisions int     `xml:"divisions"`
+	Time      xmlTime `xml:"time"`
+	Clef      xmlClef `xml:"clef"`
+}
+
+type xmlTime struct {
+	Beats    int `xml:"beats"`
+	BeatType int `xml:"beat-type"`
+}
+
+// xmlClef is derived the same way Parser.getClef derives Measure.Clef.Name:
+// bass for a track with any string tuned at or below MIDI note 34 (B flat
+// 1), treble otherwise.
+type xmlClef struct {
+	Sign string `xml:"sign"`
+	Line int    `xml:"line"`
+}
+
+// xmlDirection carries a tempo change as both a human-readable label and a
+// <sound tempo="..."/> a player can act on.
+type xmlDirection struct {
+	Words string   `xml:"direction-type>words"`
+	Sound xmlSound `xml:"sound"`
+}
+
+type xmlSound struct {
+	Tempo float64 `xml:"tempo,attr"`
+}
+
+type xmlNote struct {
+	Grace     *xmlGrace     `xml:"grace,omitempty"`
+	Pitch     *xmlPitch     `xml:"pitch,omitempty"`
+	Rest      *struct{}     `xml:"rest,omitempty"`
+	Duration  int           `xml:"duration,omitempty"`
+	Notehead  *xmlNotehead  `xml:"notehead,omitempty"`
+	Notations *xmlNotations `xml:"notations,omitempty"`
+}
+
+// xmlNotehead renders a DeadNote as the conventional "x" notehead and a
+// GhostNote as a parenthesized normal notehead, the same visual distinction
+// a Guitar Pro editor draws between the two.
+type xmlNotehead struct {
+	Parentheses string `xml:"parentheses,attr,omitempty"`
+	Value       string `xml:",chardata"`
+}
+
+type xmlGrace struct {
+	Slash string `xml:"slash,attr,omitempty"`
+}
+
+type xmlPitch struct {
+	Step   string `xml:"step"`
+	Alter  int    `xml:"alter,omitempty"`
+	Octave int    `xml:"octave"`
+}
+
+type xmlNotations struct {
+	Technical     *xmlTechnical     `xml:"technical,omitempty"`
+	Ornaments     *xmlOrnaments     `xml:"ornaments,omitempty"`
+	Articulations *xmlArticulations `xml:"articulations,omitempty"`
+}
+
+// xmlTechnical holds the NoteEffect fields that map onto MusicXML's
+// <technical> playing-technique notations.
+type xmlTechnical struct {
+	Bend           *xmlBend           `xml:"bend,omitempty"`
+	HammerOn       *xmlSlurLike       `xml:"hammer-on,omitempty"`
+	PullOff        *xmlSlurLike       `xml:"pull-off,omitempty"`
+	Slide          *xmlSlurLike       `xml:"slide,omitempty"`
+	Harmonic       *xmlHarmonic       `xml:"harmonic,omitempty"`
+	OtherTechnical *xmlOtherTechnical `xml:"other-technical,omitempty"`
+}
+
+// xmlOtherTechnical carries the playing techniques MusicXML has no
+// dedicated element for - palm mute and let ring - as the free-text
+// <other-technical> element the spec provides for exactly this case.
+type xmlOtherTechnical struct {
+	Value string `xml:",chardata"`
+}
+
+// xmlArticulations holds the NoteEffect fields that map onto MusicXML's
+// <articulations> notations: staccato and the two accent strengths.
+type xmlArticulations struct {
+	Staccato     *struct{} `xml:"staccato,omitempty"`
+	Accent       *struct{} `xml:"accent,omitempty"`
+	StrongAccent *struct{} `xml:"strong-accent,omitempty"`
+}
+
+// xmlBend reports the bend's final point as a semitone alteration; it does
+// not attempt to reconstruct the full release/pre-bend curve MusicXML's
+// richer <bend> child elements support.
+type xmlBend struct {
+	BendAlter float64 `xml:"bend-alter"`
+}
+
+// xmlSlurLike covers the handful of <technical> elements (hammer-on,
+// pull-off, slide) that are just a number/type pair; this package only ever
+// emits their "start" half, since NoteEffect does not carry the matching
+// note a "stop" would need.
+type xmlSlurLike struct {
+	Number int    `xml:"number,attr"`
+	Type   string `xml:"type,attr"`
+}
+
+type xmlHarmonic struct {
+	Natural    *struct{} `xml:"natural,omitempty"`
+	Artificial *struct{} `xml:"artificial,omitempty"`
+}
+
+type xmlOrnaments struct {
+	TrillMark *struct{}   `xml:"trill-mark,omitempty"`
+	Tremolo   *xmlTremolo `xml:"tremolo,omitempty"`
+	WavyLine  *struct{}   `xml:"wavy-line,omitempty"`
+}
+
+// xmlTremolo's body is the number of tremolo strokes (1-3), matching
+// TremoloPicking.Duration's eighth/sixteenth/thirty_second values.
+type xmlTremolo struct {
+	Type  string `xml:"type,attr"`
+	Marks int    `xml:",chardata"`
+}
+
+// noteToPitch maps a Note.Value (fret) plus the tuning of the string it was
+// played on into a MusicXML pitch, the same way a Guitar Pro editor
+// resolves tab to standard notation.
+func noteToPitch(value uint8, stringTuning int32) xmlPitch {
+	midi := int(stringTuning) + int(value)
+	name := pitchNames[((midi%12)+12)%12]
+	return xmlPitch{
+		Step:   name.step,
+		Alter:  name.alter,
+		Octave: midi/12 - 1,
+	}
+}
+
+// Export writes gp as a MusicXML 4.0 partwise document to w. Each
+// parsegp.Track becomes a <part>, each parsegp.Measure a <measure>; notes
+// are resolved to pitch via the string they were fretted on, and their
+// NoteEffect is translated into <notations>/<technical>, <notations>/
+// <ornaments> and a preceding grace note, as applicable.
+func Export(gp *parsegp.GPFile, w io.Writer) error {
+	doc := scorePartwise{Version: "4.0"}
+
+	for ti, track := range gp.Tracks {
+		partID := partIDFor(ti)
+		doc.PartList.ScoreParts = append(doc.PartList.ScoreParts, scorePart{
+			ID:       partID,
+			PartName: track.Name,
+		})
+
+		part := xmlPart{ID: partID}
+		clef := clefFor(track)
+
+		var lastFret [maxTrackedStrings]int
+		var haveLastFret [maxTrackedStrings]bool
+		lastTempo := -1
+
+		for mi, measure := range track.Measures {
+			xm := xmlMeasure{Number: numberFor(mi)}
+			if mi == 0 {
+				xm.Attrs = &xmlAttrs{
+					Divisions: divisionsPerQuarter,
+					Time: xmlTime{
+						Beats:    measure.Header.TimeSignature.Numerator,
+						BeatType: int(measure.Header.TimeSignature.Denominator.Value),
+					},
+					Clef: clef,
+				}
+			}
+
+			if tempo := measure.Header.Tempo; tempo > 0 && tempo != lastTempo {
+				xm.Directions = append(xm.Directions, xmlDirection{
+					Words: strconv.Itoa(tempo) + " BPM",
+					Sound: xmlSound{Tempo: float64(tempo)},
+				})
+				lastTempo = tempo
+			}
+
+			for _, beat := range measure.Beats {
+				if beat.Chord.Strings != nil {
+					if harmony, ok := chord.Analyze(beat.Chord); ok {
+						xm.Harmonies = append(xm.Harmonies, harmonyFor(harmony))
+					}
+				}
+
+				for _, voice := range beat.Voices {
+					if len(voice.Notes) == 0 {
+						xm.Notes = append(xm.Notes, xmlNote{Rest: &struct{}{}, Duration: divisionsFor(voice.Duration.Value)})
+						continue
+					}
+					for _, note := range voice.Notes {
+						tuning := stringTuning(track, note.String)
+
+						if isGraceNote(note.Effect.Grace) {
+							gracePitch := noteToPitch(note.Effect.Grace.Fret, tuning)
+							xm.Notes = append(xm.Notes, xmlNote{
+								Grace: &xmlGrace{Slash: graceSlash(note.Effect.Grace)},
+								Pitch: &gracePitch,
+							})
+						}
+
+						pitch := noteToPitch(note.Value, tuning)
+						xm.Notes = append(xm.Notes, xmlNote{
+							Pitch:     &pitch,
+							Duration:  divisionsFor(voice.Duration.Value),
+							Notehead:  noteheadFor(note.Effect),
+							Notations: notationsFor(note, &lastFret, &haveLastFret),
+						})
+					}
+				}
+			}
+
+			part.Measures = append(part.Measures, xm)
+		}
+		doc.Parts = append(doc.Parts, part)
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	return enc.Encode(doc)
+}
+
+// clefFor derives a part's clef the same way Parser.getClef derives
+// Measure.Clef.Name: bass if any of the track's guitar strings tunes to 34
+// or below, treble otherwise - percussion channels always get treble, since
+// getClef never looks at string tuning for them either.
+func clefFor(track parsegp.Track) xmlClef {
+	if !track.Channel.IsPercussionChannel {
+		for _, gs := range track.GuitarStrings {
+			if gs.Value <= 34 {
+				return xmlClef{Sign: "F", Line: 4}
+			}
+		}
+	}
+	return xmlClef{Sign: "G", Line: 2}
+}
+
+// isGraceNote reports whether effect.Grace describes a grace note that
+// should precede the note it is attached to, mirroring how readGrace only
+// ever sets Transition when it actually read a grace note.
+func isGraceNote(grace parsegp.Grace) bool {
+	return grace.Transition != ""
+}
+
+// graceSlash renders the fast slide/hammer grace transitions as the slashed
+// acciaccatura MusicXML uses for a grace note taken from the beat's own
+// duration, leaving the slower "bend" transition unslashed (an
+// appoggiatura).
+func graceSlash(grace parsegp.Grace) string {
+	if grace.Transition == "slide" || grace.Transition == "hammer" {
+		return "yes"
+	}
+	return "no"
+}
+
+// noteheadFor renders a DeadNote as the conventional "x" notehead and a
+// GhostNote (played quieter, in parentheses) as a parenthesized normal
+// notehead; a dead note wins if somehow both are set, since it is the more
+// visually distinctive of the two.
+func noteheadFor(effect parsegp.NoteEffect) *xmlNotehead {
+	switch {
+	case effect.DeadNote:
+		return &xmlNotehead{Value: "x"}
+	case effect.GhostNote:
+		return &xmlNotehead{Parentheses: "yes", Value: "normal"}
+	default:
+		return nil
+	}
+}
+
+// notationsFor translates note.Effect into <technical>/<ornaments>/
+// <articulations> notations, using and updating lastFret/haveLastFret to
+// tell a hammer-on from a pull-off by the direction of fret movement on the
+// same string.
+func notationsFor(note parsegp.Note, lastFret *[maxTrackedStrings]int, haveLastFret *[maxTrackedStrings]bool) *xmlNotations {
+	effect := note.Effect
+	stringIndex := int(note.String) - 1
+	trackFret := stringIndex >= 0 && stringIndex < maxTrackedStrings
+
+	var technical *xmlTechnical
+	tech := func() *xmlTechnical {
+		if technical == nil {
+			technical = &xmlTechnical{}
+		}
+		return technical
+	}
+
+	if len(effect.Bend.Points) > 0 {
+		tech().Bend = &xmlBend{BendAlter: float64(effect.Bend.Points[len(effect.Bend.Points)-1].Value)}
+	}
+	if effect.Hammer {
+		elem := &xmlSlurLike{Number: 1, Type: "start"}
+		if trackFret && haveLastFret[stringIndex] && int(note.Value) < lastFret[stringIndex] {
+			tech().PullOff = elem
+		} else {
+			tech().HammerOn = elem
+		}
+	}
+	if effect.Slide {
+		tech().Slide = &xmlSlurLike{Number: 1, Type: "start"}
+	}
+	if effect.Harmonic.Type != "" {
+		h := &xmlHarmonic{}
+		if effect.Harmonic.Type == "natural" {
+			h.Natural = &struct{}{}
+		} else {
+			h.Artificial = &struct{}{}
+		}
+		tech().Harmonic = h
+	}
+	switch {
+	case effect.PalmMute:
+		tech().OtherTechnical = &xmlOtherTechnical{Value: "palm mute"}
+	case effect.LetRing:
+		tech().OtherTechnical = &xmlOtherTechnical{Value: "let ring"}
+	}
+
+	var ornaments *xmlOrnaments
+	orn := func() *xmlOrnaments {
+		if ornaments == nil {
+			ornaments = &xmlOrnaments{}
+		}
+		return ornaments
+	}
+	if effect.Trill.Duration.Value != "" {
+		orn().TrillMark = &struct{}{}
+	}
+	if marks := tremoloMarks(effect.TremoloPicking); marks > 0 {
+		orn().Tremolo = &xmlTremolo{Type: "single", Marks: marks}
+	}
+	if effect.Vibrato {
+		orn().WavyLine = &struct{}{}
+	}
+
+	var articulations *xmlArticulations
+	art := func() *xmlArticulations {
+		if articulations == nil {
+			articulations = &xmlArticulations{}
+		}
+		return articulations
+	}
+	if effect.Staccato {
+		art().Staccato = &struct{}{}
+	}
+	switch {
+	case effect.HeavyAccentuatedNote:
+		art().StrongAccent = &struct{}{}
+	case effect.AccentuatedNote:
+		art().Accent = &struct{}{}
+	}
+
+	if trackFret {
+		lastFret[stringIndex] = int(note.Value)
+		haveLastFret[stringIndex] = true
+	}
+
+	if technical == nil && ornaments == nil && articulations == nil {
+		return nil
+	}
+	return &xmlNotations{Technical: technical, Ornaments: ornaments, Articulations: articulations}
+}
+
+func tremoloMarks(tp parsegp.TremoloPicking) int {
+	switch tp.Duration.Value {
+	case "eighth":
+		return 1
+	case "sixteenth":
+		return 2
+	case "thirty_second":
+		return 3
+	default:
+		return 0
+	}
+}
+
+// harmonyFor converts a chord.Harmony into the <harmony> element it maps
+// to, splitting Root/Bass's sharp-spelled note names into MusicXML's
+// separate step/alter pair.
+func harmonyFor(h chord.Harmony) xmlHarmony {
+	step, alter := stepAlter(h.Root)
+	xh := xmlHarmony{
+		Root: xmlHarmonyRoot{Step: step, Alter: alter},
+		Kind: kindFor(h),
+	}
+	if h.Bass != "" {
+		bassStep, bassAlter := stepAlter(h.Bass)
+		xh.Bass = &xmlHarmonyBass{Step: bassStep, Alter: bassAlter}
+	}
+	if h.Add != 0 {
+		xh.Degree = &xmlHarmonyDegree{Value: h.Add, Type: "add"}
+	}
+	return xh
+}
+
+// stepAlter splits a sharp-spelled note name (chord.noteNames' format, e.g.
+// "F#") into MusicXML's separate step letter and semitone alter.
+func stepAlter(name string) (string, int) {
+	if strings.HasSuffix(name, "#") {
+		return strings.TrimSuffix(name, "#"), 1
+	}
+	return name, 0
+}
+
+// kindFor maps a chord.Harmony's Kind/Extension pair to the MusicXML
+// <kind> vocabulary (musicxml.xsd's kind-value), falling back to "other"
+// for a kind/extension pair with no standard MusicXML name.
+func kindFor(h chord.Harmony) string {
+	switch h.Kind {
+	case "major":
+		switch h.Extension {
+		case 7:
+			return "major-seventh"
+		case 9:
+			return "major-ninth"
+		default:
+			return "major"
+		}
+	case "minor":
+		switch h.Extension {
+		case 7:
+			return "minor-seventh"
+		case 9:
+			return "minor-ninth"
+		default:
+			return "minor"
+		}
+	case "dominant":
+		switch h.Extension {
+		case 9:
+			return "dominant-ninth"
+		case 11:
+			return "dominant-11th"
+		case 13:
+			return "dominant-13th"
+		default:
+			return "dominant"
+		}
+	case "diminished":
+		if h.Extension == 7 {
+			return "diminished-seventh"
+		}
+		return "diminished"
+	case "augmented":
+		return "augmented"
+	case "sus2":
+		return "suspended-second"
+	case "sus4":
+		return "suspended-fourth"
+	default:
+		return "other"
+	}
+}
+
+func stringTuning(track parsegp.Track, stringNumber int32) int32 {
+	for _, gs := range track.GuitarStrings {
+		if gs.Number == stringNumber {
+			return gs.Value
+		}
+	}
+	return 40
+}
+
+// divisionsFor converts a Duration.Value (1 = whole note, 4 = quarter, ...)
+// into divisionsPerQuarter units.
+func divisionsFor(value float64) int {
+	if value <= 0 {
+		value = 4
+	}
+	return int(math.Round(4 * divisionsPerQuarter / value))
+}
+
+func partIDFor(i int) string {
+	return "P" + strconv.Itoa(i+1)
+}
+
+func numberFor(i int) string {
+	return strconv.Itoa(i + 1)
+}
@@ -0,0 +1,290 @@
+// Package render synthesizes a fully-loaded parsegp.GPFile directly to PCM
+// audio samples. Unlike export/midi and export/musicxml, which both stay in
+// symbolic time (MIDI ticks / divisions) and leave playback to some other
+// player, this package needs real wall-clock durations, so it converts
+// ticks to seconds itself using the same QUARTER_TIME-per-quarter-note
+// scaling the parser's getTime uses internally.
+package render
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"math"
+	"math/rand"
+
+	"github.com/knasan/parsegp"
+)
+
+// Voice synthesizes one note: freq in Hz, duration in seconds, sampleRate
+// in Hz, velocity as the GP4/GP5 0-127 velocity value. It returns a mono,
+// signed 16-bit sample buffer of exactly duration*sampleRate samples, so
+// Renderer can mix voices without doing its own envelope bookkeeping.
+type Voice interface {
+	Render(freq, duration float64, sampleRate, velocity int) []int16
+}
+
+type waveform func(phase float64) float64
+
+func sineWave(phase float64) float64 { return math.Sin(2 * math.Pi * phase) }
+
+func squareWave(phase float64) float64 {
+	if math.Mod(phase, 1) < 0.5 {
+		return 1
+	}
+	return -1
+}
+
+func triangleWave(phase float64) float64 {
+	p := math.Mod(phase, 1)
+	return 4*math.Abs(p-0.5) - 1
+}
+
+// oscillatorVoice renders freq through wave at an amplitude scaled by
+// velocity, with a short linear fade-out so consecutive notes don't click.
+type oscillatorVoice struct {
+	wave waveform
+}
+
+// SineVoice, SquareVoice and TriangleVoice are the bundled oscillator-bank
+// Voices; KarplusStrongVoice is the bundled plucked-string model Renderer
+// defaults to for guitar tracks.
+var (
+	SineVoice          Voice = oscillatorVoice{wave: sineWave}
+	SquareVoice        Voice = oscillatorVoice{wave: squareWave}
+	TriangleVoice      Voice = oscillatorVoice{wave: triangleWave}
+	KarplusStrongVoice Voice = karplusStrongVoice{}
+)
+
+func (v oscillatorVoice) Render(freq, duration float64, sampleRate, velocity int) []int16 {
+	n := int(duration * float64(sampleRate))
+	samples := make([]int16, n)
+	amplitude := amplitudeFor(velocity)
+	fadeSamples := n / 20
+
+	for i := 0; i < n; i++ {
+		phase := freq * float64(i) / float64(sampleRate)
+		sample := v.wave(phase) * amplitude
+		if fadeSamples > 0 && i >= n-fadeSamples {
+			sample *= float64(n-i) / float64(fadeSamples)
+		}
+		samples[i] = int16(sample)
+	}
+	return samples
+}
+
+// karplusStrongVoice synthesizes a plucked string using the classic
+// Karplus-Strong algorithm: a burst of noise one period long is fed
+// through a delay line of that same length, averaged and decayed on every
+// pass so the pitch holds while the energy dies away.
+type karplusStrongVoice struct {
+	// Decay is the per-sample energy retained by the delay line's
+	// averaging filter, in (0, 1). Zero selects the default, 0.996.
+	Decay float64
+}
+
+func (v karplusStrongVoice) Render(freq, duration float64, sampleRate, velocity int) []int16 {
+	decay := v.Decay
+	if decay <= 0 {
+		decay = 0.996
+	}
+
+	n := int(duration * float64(sampleRate))
+	samples := make([]int16, n)
+	amplitude := amplitudeFor(velocity)
+
+	period := int(float64(sampleRate) / freq)
+	if period < 2 {
+		period = 2
+	}
+	ring := make([]float64, period)
+	for i := range ring {
+		ring[i] = rand.Float64()*2 - 1
+	}
+
+	pos := 0
+	for i := 0; i < n; i++ {
+		next := ring[(pos+1)%period]
+		ring[pos] = decay * (ring[pos] + next) / 2
+		samples[i] = int16(ring[pos] * amplitude)
+		pos = (pos + 1) % period
+	}
+	return samples
+}
+
+func amplitudeFor(velocity int) float64 {
+	if velocity <= 0 {
+		velocity = 95
+	}
+	return float64(velocity) / 127 * 0.8 * math.MaxInt16
+}
+
+// Renderer mixes every track of a GPFile into one mono PCM buffer using
+// Voice to synthesize each note.
+type Renderer struct {
+	SampleRate int
+	Voice      Voice
+}
+
+// NewRenderer returns a Renderer at sampleRate using KarplusStrongVoice, a
+// sensible default for guitar tabs.
+func NewRenderer(sampleRate int) *Renderer {
+	return &Renderer{SampleRate: sampleRate, Voice: KarplusStrongVoice}
+}
+
+// Render synthesizes every track of gp, summing overlapping notes and
+// clipping to the int16 range, and returns the mixed mono PCM buffer.
+func (r *Renderer) Render(gp *parsegp.GPFile) []int16 {
+	sampleRate := r.SampleRate
+	if sampleRate <= 0 {
+		sampleRate = 44100
+	}
+	voice := r.Voice
+	if voice == nil {
+		voice = KarplusStrongVoice
+	}
+
+	tempo := gp.TempoValue
+	if tempo <= 0 {
+		tempo = 120
+	}
+
+	var mix []int16
+	for _, track := range gp.Tracks {
+		mix = mixInto(mix, renderTrack(track, tempo, sampleRate, voice), 0)
+	}
+	return mix
+}
+
+func renderTrack(track parsegp.Track, tempo, sampleRate int, voice Voice) []int16 {
+	var mix []int16
+	tick := 0
+
+	for _, measure := range track.Measures {
+		for _, beat := range measure.Beats {
+			longest := 0
+			for _, v := range beat.Voices {
+				if v.Empty {
+					continue
+				}
+				duration := ticksFor(v.Duration.Value)
+				if duration > longest {
+					longest = duration
+				}
+
+				seconds := secondsFor(duration, tempo)
+				offset := sampleOffsetFor(tick, tempo, sampleRate)
+				for _, note := range v.Notes {
+					freq := frequencyFor(track, note)
+					mix = mixInto(mix, voice.Render(freq, seconds, sampleRate, note.Velocity), offset)
+				}
+			}
+			if longest == 0 {
+				longest = parsegp.QUARTER_TIME
+			}
+			tick += longest
+		}
+	}
+	return mix
+}
+
+func frequencyFor(track parsegp.Track, note parsegp.Note) float64 {
+	pitch := stringTuning(track, note.String) + int32(note.Value)
+	return 440 * math.Pow(2, (float64(pitch)-69)/12)
+}
+
+func stringTuning(track parsegp.Track, stringNumber int32) int32 {
+	for _, gs := range track.GuitarStrings {
+		if gs.Number == stringNumber {
+			return gs.Value
+		}
+	}
+	return 40
+}
+
+// ticksFor converts a Duration.Value (1 = whole note, 4 = quarter, ...)
+// into QUARTER_TIME-per-quarter-note ticks, the same unit Parser.getTime
+// works in.
+func ticksFor(value float64) int {
+	if value <= 0 {
+		return parsegp.QUARTER_TIME
+	}
+	return int(4 * parsegp.QUARTER_TIME / value)
+}
+
+func secondsFor(ticks, tempo int) float64 {
+	return float64(ticks) / float64(parsegp.QUARTER_TIME) * 60 / float64(tempo)
+}
+
+func sampleOffsetFor(tick, tempo, sampleRate int) int {
+	return int(secondsFor(tick, tempo) * float64(sampleRate))
+}
+
+func mixInto(dst, src []int16, offset int) []int16 {
+	need := offset + len(src)
+	if need > len(dst) {
+		grown := make([]int16, need)
+		copy(grown, dst)
+		dst = grown
+	}
+	for i, s := range src {
+		dst[offset+i] = clip16(int32(dst[offset+i]) + int32(s))
+	}
+	return dst
+}
+
+func clip16(v int32) int16 {
+	if v > math.MaxInt16 {
+		return math.MaxInt16
+	}
+	if v < math.MinInt16 {
+		return math.MinInt16
+	}
+	return int16(v)
+}
+
+// WAVWriter frames a mono, 16-bit PCM sample buffer as a WAV file so it can
+// be streamed out via io.WriterTo - to an *os.File for offline listening,
+// or straight into a downstream spectrum/beat-analysis pipeline that wants
+// raw PCM.
+type WAVWriter struct {
+	Samples    []int16
+	SampleRate int
+}
+
+// WriteTo writes w's Samples as a complete RIFF/WAVE stream to dst.
+func (w WAVWriter) WriteTo(dst io.Writer) (int64, error) {
+	sampleRate := w.SampleRate
+	if sampleRate <= 0 {
+		sampleRate = 44100
+	}
+
+	const (
+		numChannels   = 1
+		bitsPerSample = 16
+	)
+	byteRate := sampleRate * numChannels * bitsPerSample / 8
+	blockAlign := numChannels * bitsPerSample / 8
+	dataSize := len(w.Samples) * 2
+
+	buf := &bytes.Buffer{}
+	buf.WriteString("RIFF")
+	binary.Write(buf, binary.LittleEndian, uint32(36+dataSize))
+	buf.WriteString("WAVE")
+	buf.WriteString("fmt ")
+	binary.Write(buf, binary.LittleEndian, uint32(16))
+	binary.Write(buf, binary.LittleEndian, uint16(1)) // PCM
+	binary.Write(buf, binary.LittleEndian, uint16(numChannels))
+	binary.Write(buf, binary.LittleEndian, uint32(sampleRate))
+	binary.Write(buf, binary.LittleEndian, uint32(byteRate))
+	binary.Write(buf, binary.LittleEndian, uint16(blockAlign))
+	binary.Write(buf, binary.LittleEndian, uint16(bitsPerSample))
+	buf.WriteString("data")
+	binary.Write(buf, binary.LittleEndian, uint32(dataSize))
+	for _, s := range w.Samples {
+		binary.Write(buf, binary.LittleEndian, s)
+	}
+
+	n, err := dst.Write(buf.Bytes())
+	return int64(n), err
+}
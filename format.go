@@ -0,0 +1,149 @@
+package parsegp
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+)
+
+// Decoder reads a Guitar Pro file of a specific dialect into a *GPFile.
+type Decoder interface {
+	Decode() (*GPFile, error)
+}
+
+// Encoder writes a *GPFile back out in a specific dialect.
+type Encoder interface {
+	Encode(gp *GPFile) error
+}
+
+// Format describes a Guitar Pro dialect (gp3, gp4, gp5, gpx, ...). It is
+// the pluggable counterpart to the old hard-coded SupportedFormats/
+// headerLen switch: third parties can implement Format for a new dialect
+// (gp7, a custom variant, ...) and Register it at init time instead of
+// patching this package.
+type Format interface {
+	// Extensions returns the file extensions this format claims, including
+	// the leading dot (e.g. ".gp5").
+	Extensions() []string
+	// Magic returns the header bytes that identify the format, or nil if
+	// the format cannot be distinguished by a fixed magic (and must be
+	// matched by extension alone).
+	Magic() []byte
+	NewDecoder(fo io.ReadSeeker) (Decoder, error)
+	NewEncoder(w io.Writer) (Encoder, error)
+}
+
+var registry []Format
+
+// Register adds a Format to the registry. It is meant to be called from
+// an init() function of the package implementing the format, mirroring
+// the way image/audio codec packages register themselves.
+func Register(f Format) {
+	registry = append(registry, f)
+}
+
+// Lookup returns the registered Format that claims the given extension
+// (including the leading dot), or nil if none does.
+func Lookup(ext string) Format {
+	for _, f := range registry {
+		for _, e := range f.Extensions() {
+			if e == ext {
+				return f
+			}
+		}
+	}
+	return nil
+}
+
+// Detect returns the registered Format whose magic bytes match the start
+// of fo, or nil if none does. The reader is left at its original position.
+func Detect(fo io.ReadSeeker) (Format, error) {
+	pos, err := fo.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return nil, err
+	}
+	defer fo.Seek(pos, io.SeekStart)
+
+	if _, err := fo.Seek(0, io.SeekStart); err != nil {
+		return nil, err
+	}
+
+	head := make([]byte, 32)
+	n, err := io.ReadFull(fo, head)
+	if err != nil && err != io.ErrUnexpectedEOF {
+		return nil, err
+	}
+	head = head[:n]
+
+	for _, f := range registry {
+		magic := f.Magic()
+		if len(magic) == 0 {
+			continue
+		}
+		if bytes.Contains(head, magic) {
+			return f, nil
+		}
+	}
+
+	return nil, fmt.Errorf("no registered format matches this file")
+}
+
+// RegisteredExtensions returns the extensions of every registered Format,
+// in registration order. SupportedFormats falls back to the legacy,
+// hard-coded list when nothing has registered yet, so existing callers
+// keep working while gp3/gp4/gp5/gpx are migrated to self-registering
+// subpackages.
+func RegisteredExtensions() []string {
+	var exts []string
+	for _, f := range registry {
+		exts = append(exts, f.Extensions()...)
+	}
+	return exts
+}
+
+func init() {
+	Register(legacyFormat{})
+}
+
+// legacyFormat adapts the existing gp3/gp4/gp5/gpx handling in this
+// package to the Format interface until it is split out into its own
+// self-registering subpackage.
+type legacyFormat struct{}
+
+func (legacyFormat) Extensions() []string { return []string{".gp3", ".gp4", ".gp5", ".gpx"} }
+func (legacyFormat) Magic() []byte        { return []byte("FICHIER GUITAR") }
+
+func (legacyFormat) NewDecoder(fo io.ReadSeeker) (Decoder, error) {
+	return &legacyDecoder{fo: fo}, nil
+}
+
+func (legacyFormat) NewEncoder(w io.Writer) (Encoder, error) {
+	return nil, fmt.Errorf("legacyFormat: encoding is not implemented yet")
+}
+
+type legacyDecoder struct {
+	fo io.ReadSeeker
+}
+
+func (d *legacyDecoder) Decode() (*GPFile, error) {
+	gp := &GPFile{}
+
+	headerlen, head, err := headerLen(d.fo)
+	if err != nil {
+		return nil, err
+	}
+	if headerlen == 0 {
+		return nil, &notGPFile{"Invalid Guitar Pro file"}
+	}
+	if headerlen == 4 {
+		if err := gp.loadGPXFile(); err != nil {
+			return nil, err
+		}
+		return gp, nil
+	}
+
+	if err := gp.uncompressedGpInfo(d.fo, head); err != nil {
+		return nil, err
+	}
+	return gp, nil
+}
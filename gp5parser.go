@@ -8,10 +8,12 @@ package parsegp
 
 import (
 	"bufio"
+	"bytes"
 	"encoding/binary"
 	"errors"
 	"fmt"
 	"io"
+	"log/slog"
 	"math"
 	"os"
 	"regexp"
@@ -30,13 +32,22 @@ const (
 	GP_BEND_POSITION                 = 60
 )
 
+// VERSIONS lists every version string Parser recognises, in VersionIndex
+// order. versionProfiles (song.go) carries the layout differences that go
+// with each entry, so adding a format here means adding a matching profile
+// there.
 var VERSIONS = []string{
+	"FICHIER GUITAR PRO v3.00",
+	"FICHIER GUITAR PRO v4.00",
+	"FICHIER GUITAR PRO v4.06",
 	"FICHIER GUITAR PRO v5.00",
 	"FICHIER GUITAR PRO v5.10",
 }
 
 type Parser struct {
-	FileBuffer         []byte
+	br                 *bufio.Reader
+	reader             io.Reader
+	closer             io.Closer
 	BufferPosition     int
 	VersionIndex       int
 	Channels           []Channel
@@ -61,37 +72,80 @@ type Parser struct {
 	Comments           []string
 	TabFile            *TabFile
 	Version            string
+	// TempoName, HideTempo, Key, Octave, Directions and MasterReverb are
+	// only present in gp5; Parse leaves them at their zero value for
+	// gp3/gp4 files.
+	TempoName    string
+	HideTempo    bool
+	Key          int
+	Octave       int8
+	Directions   map[string]int16
+	MasterReverb int32
+	// Strict makes Parse abort with an error as soon as any field fails to
+	// read. The default, false, instead records a ParseWarning for each
+	// one and carries on with whatever the file gave us.
+	Strict bool
+	// Warnings accumulates the fields Parse could not read when Strict is
+	// false. It is also copied onto the returned TabFile.
+	Warnings []ParseWarning
+	// ParseErrors is Warnings' position-aware counterpart, appended to
+	// alongside it by fail from the same failed reads: each ParseError
+	// additionally locates the failure by track/measure/beat via Section.
+	ParseErrors []ParseError
+	// Logger, if set, receives a Warn record for every ParseWarning fail
+	// records. Left nil (the default), non-strict parsing stays silent
+	// except for the accumulated Warnings.
+	Logger *slog.Logger
+	// Visitor, if set, receives OnHeader/OnChannel/OnMeasureHeader/OnTrack/
+	// OnBeat/OnNote/OnNoteEffect/OnMixChange callbacks as Parse or Walk
+	// reads the corresponding piece of the file. Parse always builds and
+	// returns a full TabFile regardless of Visitor; Walk is for callers
+	// who want the callbacks without Parse's memory cost.
+	Visitor Visitor
+	// Mode holds the SkipNotes/SkipLyrics/ParseChordDiagrams/Trace/
+	// AllErrors bits NewParserWithMode was called with. The zero value
+	// parses everything and behaves exactly as Parse always has.
+	Mode Mode
+	// percussionChannels caches Channels' IsPercussionChannel flags by ID,
+	// built once by indexPercussionChannels right after Channels is read,
+	// so isPercussionChannel no longer scans Channels on every call.
+	percussionChannels map[int32]bool
+	// tiedNoteCache holds, per guitar string (index = string number - 1),
+	// the fret value of the most recent note readNote saw on that string
+	// within the track currently being read. Parse resets it before
+	// each track, so getTiedNoteValue/setTiedNoteValue stay O(1) instead of
+	// getTiedNoteValue's old backward scan over every prior measure.
+	tiedNoteCache [7]struct {
+		Value uint8
+		Set   bool
+	}
+	// curTrack, curMeasure and curBeat are the 1-based position Parse is
+	// currently reading, kept up to date by Parse/readMeasure so fail can
+	// build a ParseError.Section that locates the failure. All three are 0
+	// before Parse enters the per-track measure loop.
+	curTrack, curMeasure, curBeat int
 }
 
-// NewParser creates a new Parser instance from the given file path.
-// It reads and parses the file's header information, including version, title, subtitle, artist, album,
-// lyrics author, music author, copyright, tab, and instructions.
+// NewParser creates a new Parser that reads a Guitar Pro file directly from
+// r. It pulls bytes on demand through a buffered reader rather than loading
+// the whole file into memory up front, so it parses the header information
+// (version, title, subtitle, artist, album, lyrics author, music author,
+// copyright, tab, and instructions) as it goes.
 //
 // Parameters:
-// filePath (string): The path to the Guitar Pro tab file.
+// r (io.Reader): The Guitar Pro tab file data.
 //
 // Returns:
 // *Parser: A pointer to a new Parser instance, or nil if an error occurs.
-// error: An error if the file path is empty, if the file cannot be opened, or if an error occurs during parsing.
-func NewParser(filePath string) (*Parser, error) {
-	if filePath == "" {
-		return nil, errors.New("null file path passed to constructor")
-	}
-
-	file, err := os.Open(filePath)
-	if err != nil {
-		return nil, err
-	}
-	defer file.Close()
-
-	fileBuffer, err := io.ReadAll(bufio.NewReader(file))
-	if err != nil {
-		return nil, err
+// error: An error if r is nil or if an error occurs during parsing.
+func NewParser(r io.Reader) (*Parser, error) {
+	if r == nil {
+		return nil, errors.New("nil reader passed to constructor")
 	}
 
 	parser := &Parser{
-		FileBuffer:     fileBuffer,
-		BufferPosition: 0,
+		br:     bufio.NewReader(r),
+		reader: r,
 	}
 
 	// Read and check version
@@ -162,58 +216,234 @@ func NewParser(filePath string) (*Parser, error) {
 		return nil, err
 	}
 
-	parser.Instructions, err = parser.readStringByteSizeOfInteger()
+	// gp3 has no instructions or comments block; gp4 and gp5 both do.
+	if parser.profile().gp4Plus {
+		parser.Instructions, err = parser.readStringByteSizeOfInteger()
+		if err != nil {
+			return nil, err
+		}
+
+		commentCount, err := parser.readInt()
+		if err != nil {
+			return nil, err
+		}
+		for i := int32(0); i < commentCount; i++ {
+			comment, err := parser.readStringInteger()
+			if err != nil {
+				return nil, err
+			}
+			parser.Comments = append(parser.Comments, comment)
+		}
+	}
+
+	return parser, nil
+}
+
+// NewParserFromBytes creates a new Parser from a Guitar Pro file already
+// held in memory. It is a thin adapter over NewParser for callers that do
+// not have (or do not want) a streaming source.
+//
+// Parameters:
+// data ([]byte): The Guitar Pro tab file data.
+//
+// Returns:
+// *Parser: A pointer to a new Parser instance, or nil if an error occurs.
+// error: An error if an error occurs during parsing.
+func NewParserFromBytes(data []byte) (*Parser, error) {
+	return NewParser(bytes.NewReader(data))
+}
+
+// NewParserFromFile creates a new Parser instance from the given file path.
+// The file is kept open for the lifetime of the Parser, since parsing pulls
+// from it on demand; call Close once the Parser is no longer needed.
+//
+// Parameters:
+// filePath (string): The path to the Guitar Pro tab file.
+//
+// Returns:
+// *Parser: A pointer to a new Parser instance, or nil if an error occurs.
+// error: An error if the file path is empty, if the file cannot be opened, or if an error occurs during parsing.
+func NewParserFromFile(filePath string) (*Parser, error) {
+	if filePath == "" {
+		return nil, errors.New("null file path passed to constructor")
+	}
+
+	file, err := os.Open(filePath)
+	if err != nil {
+		return nil, err
+	}
+
+	parser, err := NewParser(file)
 	if err != nil {
+		file.Close()
 		return nil, err
 	}
-	// Assume additional initialization occurs here
+	parser.closer = file
 
 	return parser, nil
 }
 
-// ReadInt reads the next 4 bytes from the file buffer as a 32-bit integer (int32).
+// NewParserFromReader is an alias for NewParser: both already read a
+// Guitar Pro file directly from r through a buffered, on-demand reader
+// rather than materializing it in memory first. It exists under this more
+// explicit name for call sites where "this parses from a Reader" - a
+// network connection, an embedded FS entry, anywhere the whole file isn't
+// sitting in a []byte already - is the point being made.
+func NewParserFromReader(r io.Reader) (*Parser, error) {
+	return NewParser(r)
+}
+
+// readSource resolves src into bytes, the way go/parser's readSource helper
+// resolves a ParseFile source argument: string and []byte are taken
+// literally, *bytes.Buffer is drained, an io.Reader is read to completion,
+// and nil falls back to reading filename off disk. NewParserFromSource uses
+// this to accept either a filesystem path or an already-open source for the
+// same call.
+func readSource(filename string, src any) ([]byte, error) {
+	if src != nil {
+		switch s := src.(type) {
+		case string:
+			return []byte(s), nil
+		case []byte:
+			return s, nil
+		case *bytes.Buffer:
+			return s.Bytes(), nil
+		case io.Reader:
+			return io.ReadAll(s)
+		default:
+			return nil, errors.New("parsegp: invalid source")
+		}
+	}
+	return os.ReadFile(filename)
+}
+
+// NewParserFromSource is the general-purpose counterpart to
+// NewParserFromFile/NewParserFromBytes/NewParserFromReader: it resolves src
+// via readSource - a string, []byte, *bytes.Buffer, io.Reader, or nil to
+// read filename from disk - then constructs a Parser from the result. It
+// suits call sites that don't know ahead of time whether a given entry is a
+// path or data already in memory; ParseDir itself opens every entry with
+// NewParserFromFile.
+func NewParserFromSource(filename string, src any) (*Parser, error) {
+	data, err := readSource(filename, src)
+	if err != nil {
+		return nil, err
+	}
+	return NewParserFromBytes(data)
+}
+
+// NewParserWithMode is NewParserFromFile plus mode: it sets Strict false
+// (continue past recoverable errors, accumulating Warnings) only when mode
+// has AllErrors set, and stores mode on the Parser for Parse/readSongStructure
+// to consult (SkipNotes, SkipLyrics, Trace). ParseChordDiagrams is accepted
+// but currently a no-op; see its doc comment.
+func NewParserWithMode(path string, mode Mode) (*Parser, error) {
+	parser, err := NewParserFromFile(path)
+	if err != nil {
+		return nil, err
+	}
+	parser.Mode = mode
+	parser.Strict = mode&AllErrors == 0
+	return parser, nil
+}
+
+// Seek repositions the Parser's read cursor and returns the new offset. If
+// the io.Reader passed to NewParser also implements io.Seeker, it delegates
+// to that and resets the internal buffered reader; otherwise it only
+// supports seeking forward from the current position (io.SeekCurrent with
+// a non-negative offset), which it satisfies by draining and discarding the
+// intervening bytes, the same way skip does.
+func (p *Parser) Seek(offset int64, whence int) (int64, error) {
+	if seeker, ok := p.reader.(io.Seeker); ok {
+		if whence == io.SeekCurrent {
+			// The underlying reader is ahead of the Parser by whatever br
+			// has buffered but not yet handed out.
+			offset -= int64(p.br.Buffered())
+		}
+		pos, err := seeker.Seek(offset, whence)
+		if err != nil {
+			return 0, err
+		}
+		p.br.Reset(p.reader)
+		p.BufferPosition = int(pos)
+		return pos, nil
+	}
+
+	if whence != io.SeekCurrent || offset < 0 {
+		return 0, errors.New("parsegp: Seek needs an io.Seeker reader for anything but forward-from-current")
+	}
+
+	discarded, err := io.CopyN(io.Discard, p.br, offset)
+	p.BufferPosition += int(discarded)
+	return int64(p.BufferPosition), err
+}
+
+// Close releases any resource the Parser opened on its own behalf, such as
+// the file handle from NewParserFromFile. It is a no-op for a Parser built
+// from a reader it does not own.
+func (p *Parser) Close() error {
+	if p.closer != nil {
+		return p.closer.Close()
+	}
+	return nil
+}
+
+// ReadInt reads the next 4 bytes from the stream as a 32-bit integer (int32).
 // It returns the integer value and an error if there are not enough bytes to read.
 // The function also updates the buffer position by 4 after reading.
 func (p *Parser) readInt() (int32, error) {
-	// Check if there are enough bytes to read
-	if p.BufferPosition+4 > len(p.FileBuffer) {
-		return 0, errors.New("not enough bytes to read int")
+	var buf [4]byte
+	if _, err := io.ReadFull(p.br, buf[:]); err != nil {
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			return 0, errors.New("not enough bytes to read int")
+		}
+		return 0, err
 	}
 
-	// Reading the 4 bytes and converting them to a 32-bit integer
-	returnVal := int32(
-		((uint32(p.FileBuffer[p.BufferPosition+3]) & 0xFF) << 24) |
-			((uint32(p.FileBuffer[p.BufferPosition+2]) & 0xFF) << 16) |
-			((uint32(p.FileBuffer[p.BufferPosition+1]) & 0xFF) << 8) |
-			(uint32(p.FileBuffer[p.BufferPosition]) & 0xFF))
-
-	// Increase buffer position by 4 after reading
 	p.BufferPosition += 4
 
-	return returnVal, nil
+	return int32(binary.LittleEndian.Uint32(buf[:])), nil
+}
+
+// readShort reads the next 2 bytes from the stream as a signed 16-bit
+// integer (int16). It returns the value and an error if there are not
+// enough bytes to read, and updates the buffer position by 2 after
+// reading, mirroring readInt's 4-byte version.
+func (p *Parser) readShort() (int16, error) {
+	var buf [2]byte
+	if _, err := io.ReadFull(p.br, buf[:]); err != nil {
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			return 0, errors.New("not enough bytes to read short")
+		}
+		return 0, err
+	}
+
+	p.BufferPosition += 2
+
+	return int16(binary.LittleEndian.Uint16(buf[:])), nil
 }
 
-// readByte reads a single byte from the buffer and increments the position by one.
+// readByte reads a single byte from the stream and increments the position by one.
 //
-// The function checks if there are still bytes available in the buffer. If not, it returns an error.
+// The function checks if there are still bytes available in the stream. If not, it returns an error.
 // If there are bytes available, it reads the byte at the current position, increments the buffer position,
 // and returns the byte value as an byte along with a nil error.
 func (p *Parser) readByte() (byte, error) {
-	// Check if there are still bytes in the buffer
-	if p.BufferPosition >= len(p.FileBuffer) {
-		return 0, errors.New("not enough bytes to read")
+	byteValue, err := p.br.ReadByte()
+	if err != nil {
+		if err == io.EOF {
+			return 0, errors.New("not enough bytes to read")
+		}
+		return 0, err
 	}
-
-	// Read the byte and increment the buffer position
-	byteValue := p.FileBuffer[p.BufferPosition]
 	p.BufferPosition++
 
 	return byteValue, nil
 }
 
-// readUnsignedByte reads a single unsigned byte from the buffer and increments the position by one.
+// readUnsignedByte reads a single unsigned byte from the stream and increments the position by one.
 //
-// The function checks if there are still bytes available in the buffer. If not, it returns an error.
+// The function checks if there are still bytes available in the stream. If not, it returns an error.
 // If there are bytes available, it reads the byte at the current position, increments the buffer position,
 // and returns the byte value as an uint8 along with a nil error.
 //
@@ -223,22 +453,22 @@ func (p *Parser) readByte() (byte, error) {
 //
 // Returns:
 //
-//	uint8 - The byte value read from the buffer.
+//	uint8 - The byte value read from the stream.
 //	error - An error if there are not enough bytes to read.
 func (p *Parser) readUnsignedByte() (byte, error) {
-	// Check if there are still bytes in the buffer
-	if p.BufferPosition >= len(p.FileBuffer) {
-		return 0, errors.New("not enough bytes to read")
+	byteValue, err := p.br.ReadByte()
+	if err != nil {
+		if err == io.EOF {
+			return 0, errors.New("not enough bytes to read")
+		}
+		return 0, err
 	}
-
-	// Read the byte and increment the buffer position
-	byteValue := p.FileBuffer[p.BufferPosition]
 	p.BufferPosition++
 
 	return byteValue, nil
 }
 
-// readString reads a string of specified size from the file buffer.
+// readString reads a string of specified size from the stream.
 // It returns the string value and an error if there are not enough bytes to read.
 // The function also updates the buffer position by the size of the string after reading.
 //
@@ -249,23 +479,26 @@ func (p *Parser) readUnsignedByte() (byte, error) {
 //
 // Returns:
 //
-//	string - The string value read from the buffer.
+//	string - The string value read from the stream.
 //	error - An error if there are not enough bytes to read.
 func (p *Parser) readString(size int) (string, error) {
-	// Check if there are enough bytes in the buffer
-
-	if p.BufferPosition+size > len(p.FileBuffer) {
+	if size < 0 {
 		return "", errors.New("not enough bytes to read string")
 	}
 
-	// Read the bytes and create the string
-	byteSlice := p.FileBuffer[p.BufferPosition : p.BufferPosition+size]
+	byteSlice := make([]byte, size)
+	if _, err := io.ReadFull(p.br, byteSlice); err != nil {
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			return "", errors.New("not enough bytes to read string")
+		}
+		return "", err
+	}
 	p.BufferPosition += size
 
 	return string(byteSlice), nil
 }
 
-// readByteString reads a string of specified size or length from the file buffer.
+// readByteString reads a string of specified size or length from the stream.
 // If size is less than or equal to 0, it reads the specified length of bytes.
 // It returns the string value and an error if there are not enough bytes to read.
 // The function also updates the buffer position by the size of the string after reading.
@@ -278,7 +511,7 @@ func (p *Parser) readString(size int) (string, error) {
 //
 // Returns:
 //
-//	string - The string value read from the buffer.
+//	string - The string value read from the stream.
 //	error - An error if there are not enough bytes to read.
 func (p *Parser) readByteString(size, len int) (string, error) {
 	// Determine the number of bytes to read
@@ -287,13 +520,13 @@ func (p *Parser) readByteString(size, len int) (string, error) {
 		bytesToRead = len
 	}
 
-	// Check if there are enough bytes in the buffer
-	if p.BufferPosition+bytesToRead > binary.Size(p.FileBuffer) { // len(p.fileBuffer) {
-		return "", errors.New("not enough bytes to read string")
+	buf := make([]byte, bytesToRead)
+	if _, err := io.ReadFull(p.br, buf); err != nil {
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			return "", errors.New("not enough bytes to read string")
+		}
+		return "", err
 	}
-
-	// Read the bytes from the buffer
-	bytes := p.FileBuffer[p.BufferPosition : p.BufferPosition+bytesToRead]
 	p.BufferPosition += bytesToRead
 
 	// Determine the actual length of the string to return
@@ -303,7 +536,7 @@ func (p *Parser) readByteString(size, len int) (string, error) {
 	}
 
 	// Return the string
-	return string(bytes[:actualLength]), nil
+	return string(buf[:actualLength]), nil
 }
 
 // readStringByte reads a string from the file buffer using the specified size and the length read from the next byte.
@@ -364,7 +597,7 @@ func (p *Parser) readStringInteger() (string, error) {
 	return p.readString(int(num))
 }
 
-// skip skips the specified number of bytes in the file buffer.
+// skip skips the specified number of bytes in the stream.
 // It updates the buffer position by the given number of bytes.
 //
 // Parameters:
@@ -376,7 +609,11 @@ func (p *Parser) readStringInteger() (string, error) {
 //
 //	None
 func (p *Parser) skip(n int) {
-	p.BufferPosition += n
+	if n <= 0 {
+		return
+	}
+	discarded, _ := io.CopyN(io.Discard, p.br, int64(n))
+	p.BufferPosition += int(discarded)
 }
 
 // ReadVersion reads a version string from the file buffer using the specified size.
@@ -434,7 +671,7 @@ func (p *Parser) readLyrics() Lyric {
 // Otherwise, it skips over 30 bytes.
 // After skipping the initial bytes, it iterates over 11 times, skipping over 4 bytes and reading a string of length 0.
 func (p *Parser) readPageSetup() {
-	if p.VersionIndex > 0 {
+	if p.profile().gp4Plus {
 		p.skip(49)
 	} else {
 		p.skip(30)
@@ -446,76 +683,81 @@ func (p *Parser) readPageSetup() {
 }
 
 // readKeySignature reads the key signature from the file buffer and returns it.
-// It reads a single byte from the buffer and adjusts the value based on the version index.
-// If an error occurs during reading, it returns the original keySignature value minus 1.
-//
-// Parameters:
-// p - A pointer to the Parser struct from which to read the key signature.
-//
-// Return:
-// byte - The key signature value read from the file buffer.
-// If an error occurs during reading, it returns the original keySignature value minus 1.
-func (p *Parser) readKeySignature() byte {
+// If an error occurs during reading, it returns a zero key signature and
+// either the error (Strict mode) or nil with a recorded ParseWarning.
+func (p *Parser) readKeySignature() (byte, error) {
 	keySignature, err := p.readByte()
 	if err != nil {
-		return keySignature - 1
+		return 0, p.fail("keySignature", err)
 	}
 
-	// if keySignature < 0 { // This fix addresses the staticcheck warning "SA4003: no value of type byte is less than 0".
-	keySignature = 7 + keySignature // Fix: Add '+' instead of '-'
-	// }
-
-	return keySignature
+	return 7 + keySignature, nil
 }
 
 // readChannels reads and parses the channel data from the file buffer.
 // It iterates over 64 channels, reading the program, volume, balance, chorus, reverb, pan, phaser, tremolo,
 // and bank information for each channel. It also sets the IsPercussionChannel flag for the 10th channel.
 //
-// Parameters:
-//
-//	p - A pointer to the Parser struct from which to read the channel data.
-//
 // Returns:
 //
 //	[]Channel - A slice of Channel structs containing the parsed channel data.
 //		Each Channel struct contains the program, volume, balance, chorus, reverb, pan, phaser, tremolo,
 //		bank, IsPercussionChannel flag, and name.
-func (p *Parser) readChannels() []Channel {
+//
+// In Strict mode, readChannels aborts and returns the first field error it
+// hits; otherwise each failed field is recorded as a ParseWarning and
+// reading continues with the field left at its zero value.
+func (p *Parser) readChannels() ([]Channel, error) {
 	var channels []Channel
 	for i := 0; i < 64; i++ {
 		channel := Channel{}
 		var err error
 		if channel.Program, err = p.readInt(); err != nil {
-			fmt.Println("Error reading channel program:", err)
+			if err := p.fail(fmt.Sprintf("channel[%d].program", i), err); err != nil {
+				return nil, err
+			}
 		}
 
 		if channel.Volume, err = p.readByte(); err != nil {
-			fmt.Println("Error reading channel volume:", err)
+			if err := p.fail(fmt.Sprintf("channel[%d].volume", i), err); err != nil {
+				return nil, err
+			}
 		}
 
 		if channel.Balance, err = p.readByte(); err != nil {
-			fmt.Println("Error reading channel balance:", err)
+			if err := p.fail(fmt.Sprintf("channel[%d].balance", i), err); err != nil {
+				return nil, err
+			}
 		}
 
 		if channel.Chorus, err = p.readByte(); err != nil {
-			fmt.Println("Error reading channel chorus:", err)
+			if err := p.fail(fmt.Sprintf("channel[%d].chorus", i), err); err != nil {
+				return nil, err
+			}
 		}
 
 		if channel.Reverb, err = p.readByte(); err != nil {
-			fmt.Println("Error reading channel reverb:", err)
+			if err := p.fail(fmt.Sprintf("channel[%d].reverb", i), err); err != nil {
+				return nil, err
+			}
 		}
 
 		if channel.Pan, err = p.readByte(); err != nil {
-			fmt.Println("Error reading channel pan:", err)
+			if err := p.fail(fmt.Sprintf("channel[%d].pan", i), err); err != nil {
+				return nil, err
+			}
 		}
 
 		if channel.Phaser, err = p.readByte(); err != nil {
-			fmt.Println("Error reading channel phaser:", err)
+			if err := p.fail(fmt.Sprintf("channel[%d].phaser", i), err); err != nil {
+				return nil, err
+			}
 		}
 
 		if channel.Tremolo, err = p.readByte(); err != nil {
-			fmt.Println("Error reading channel tremolo:", err)
+			if err := p.fail(fmt.Sprintf("channel[%d].tremolo", i), err); err != nil {
+				return nil, err
+			}
 		}
 
 		if i == 9 {
@@ -533,19 +775,12 @@ func (p *Parser) readChannels() []Channel {
 		p.skip(2)
 	}
 
-	return channels
+	return channels, nil
 }
 
-// readColor reads the next three bytes from the file buffer as unsigned integers representing the red, green, and blue
-// components of a color. It then skips over the next byte.
-//
-// The function reads the red, green, and blue components using the readUnsignedByte method of the Parser struct.
-// If any of these read operations fail, it prints an error message to the console.
-// After reading the color components, it skips over the next byte using the skip method of the Parser struct.
-//
-// Parameters:
-//
-//	p - A pointer to the Parser struct from which to read the color components.
+// readColor reads the next three bytes from the file buffer as unsigned
+// integers representing the red, green, and blue components of a color,
+// then skips over the next byte.
 //
 // Returns:
 //
@@ -553,24 +788,33 @@ func (p *Parser) readChannels() []Channel {
 //		R: The red component of the color (0-255).
 //		G: The green component of the color (0-255).
 //		B: The blue component of the color (0-255).
-func (p *Parser) readColor() Color {
+//
+// In Strict mode, a failed component read aborts and returns the error;
+// otherwise it is recorded as a ParseWarning and the component is left 0.
+func (p *Parser) readColor() (Color, error) {
 	c := Color{}
 	var err error
 	if c.R, err = p.readUnsignedByte(); err != nil {
-		fmt.Println("Error reading color red:", err)
+		if err := p.fail("color.r", err); err != nil {
+			return c, err
+		}
 	}
 
 	if c.G, err = p.readUnsignedByte(); err != nil {
-		fmt.Println("Error reading color green:", err)
+		if err := p.fail("color.g", err); err != nil {
+			return c, err
+		}
 	}
 
 	if c.B, err = p.readUnsignedByte(); err != nil {
-		fmt.Println("Error reading color blue:", err)
+		if err := p.fail("color.b", err); err != nil {
+			return c, err
+		}
 	}
 
 	p.skip(1)
 
-	return c
+	return c, nil
 }
 
 // readChannel reads and processes channel data from the file buffer.
@@ -580,18 +824,20 @@ func (p *Parser) readColor() Color {
 // If the copied channel's ID is 0, it assigns a new ID, sets the name to "TODO", appends the temporary ChannelParam objects,
 // and adds the channel to the Parser's channels slice.
 // Finally, it sets the track's ChannelID to the copied channel's ID.
-func (p *Parser) readChannel(track *Track) {
+func (p *Parser) readChannel(track *Track) error {
 	gmChannel1, err := p.readInt()
 	if err != nil {
-		fmt.Println("Error reading gm channel 1:", err)
-		return
+		if err := p.fail("track.channel.gmChannel1", err); err != nil {
+			return err
+		}
 	}
 	gmChannel1 = gmChannel1 - 1
 
 	gmChannel2, err := p.readInt()
 	if err != nil {
-		fmt.Println("Error reading gm channel 2:", err)
-		return
+		if err := p.fail("track.channel.gmChannel2", err); err != nil {
+			return err
+		}
 	}
 	gmChannel2 = gmChannel2 - 1
 
@@ -631,22 +877,28 @@ func (p *Parser) readChannel(track *Track) {
 		}
 		track.ChannelID = channel.ID
 	}
+
+	return nil
 }
 
 // readMeasure reads and processes a measure from the MIDI file.
 // It iterates through the beats in the measure, calculates the start time for each beat,
 // and updates the measure's beats, clef, and key signature.
-func (p *Parser) readMeasure(measure *Measure, track *Track, tempo *Tempo, keySignature int8) {
-	for voice := 0; voice < 2; voice++ {
+func (p *Parser) readMeasure(measure *Measure, track *Track, tempo *Tempo, keySignature int8) error {
+	for voice := 0; voice < p.profile().voiceCount; voice++ {
 		start := float64(measure.Start)
 
 		beats, err := p.readInt()
 		if err != nil {
-			fmt.Println("Error reading beats:", err)
-			return
+			return p.fail("measure.beatCount", err)
 		}
 		for k := 0; k < int(beats); k++ {
-			start += p.readBeat(int32(start), measure, track, tempo, voice)
+			p.curBeat = k + 1
+			duration, err := p.readBeat(int32(start), measure, track, tempo, voice)
+			if err != nil {
+				return err
+			}
+			start += duration
 		}
 	}
 
@@ -676,6 +928,8 @@ func (p *Parser) readMeasure(measure *Measure, track *Track, tempo *Tempo, keySi
 
 	measure.Clef.Name = p.getClef(track)
 	measure.KeySignature = keySignature
+
+	return nil
 }
 
 // getLength calculates the length of a measure based on its time signature and tempo.
@@ -712,110 +966,141 @@ func (p *Parser) getBeat(measure *Measure, start int32) *Beat {
 	return &measure.Beats[len(measure.Beats)-1]
 }
 
-// readMixChange reads the mix change data from the MIDI file.
-// It updates the tempo value and skips the unnecessary data.
-func (p *Parser) readMixChange(tempo *Tempo) {
-	// Read the instrument byte and skip the next 16 bytes
-	p.readByte()
+// readMixChange reads a beat's mix table change: an instrument (patch)
+// change, six signed bytes (volume, balance, chorus, reverb, phaser,
+// tremolo) plus a tempo name and value, each of which only carries a
+// following duration byte when its value is present (a signed value of -1
+// means that parameter is left unchanged). It stores the result on beat
+// and, if the tempo changed, on tempo as well. The trailing byte is a
+// GP4/GP5 applied-to-all bitmask; gp3 mix changes stop before it.
+func (p *Parser) readMixChange(beat *Beat, tempo *Tempo, channelID int32) error {
+	instrument, err := p.readByte()
+	if err != nil {
+		return p.fail("mixChange.instrument", err)
+	}
 	p.skip(16)
 
-	// Read the volume, pan, chorus, reverb, phaser, and tremolo bytes
 	volume, err := p.readByte()
 	if err != nil {
-		fmt.Println("Error reading mix change volume:", err)
-		return
+		return p.fail("mixChange.volume", err)
 	}
-
-	pan, err := p.readByte()
+	balance, err := p.readByte()
 	if err != nil {
-		fmt.Println("Error reading mix change pan:", err)
-		return
+		return p.fail("mixChange.balance", err)
 	}
-
 	chorus, err := p.readByte()
 	if err != nil {
-		fmt.Println("Error reading mix change chorus:", err)
-		return
+		return p.fail("mixChange.chorus", err)
 	}
-
 	reverb, err := p.readByte()
 	if err != nil {
-		fmt.Println("Error reading mix change reverb:", err)
-		return
+		return p.fail("mixChange.reverb", err)
 	}
-
 	phaser, err := p.readByte()
 	if err != nil {
-		fmt.Println("Error reading mix change phaser:", err)
-		return
+		return p.fail("mixChange.phaser", err)
 	}
-
 	tremolo, err := p.readByte()
 	if err != nil {
-		fmt.Println("Error reading mix change tremolo:", err)
-		return
+		return p.fail("mixChange.tremolo", err)
 	}
 
-	// Read the tempo name as a byte-sized string and skip it
-	p.readStringByteSizeOfInteger()
+	if _, err := p.readStringByteSizeOfInteger(); err != nil {
+		return p.fail("mixChange.tempoName", err)
+	}
 
-	// Read the tempo value as an integer and update the tempo
 	tempoValue, err := p.readInt()
 	if err != nil {
-		fmt.Println("Error reading mix change tempo value:", err)
-		return
+		return p.fail("mixChange.tempoValue", err)
 	}
 
-	if volume >= 0 {
-		p.readByte()
+	change := MixTableChange{}
+
+	if int8(instrument) >= 0 && !p.isPercussionChannel(channelID) {
+		value := int8(instrument)
+		change.Instrument = &value
 	}
-	if pan >= 0 {
-		p.readByte()
+
+	readItem := func(field string, raw byte) (*MixTableItem, error) {
+		if int8(raw) < 0 {
+			return nil, nil
+		}
+		duration, err := p.readByte()
+		if err != nil {
+			return nil, p.fail("mixChange."+field+".duration", err)
+		}
+		return &MixTableItem{Value: int32(int8(raw)), Duration: duration}, nil
 	}
-	if chorus >= 0 {
-		p.readByte()
+
+	if change.Volume, err = readItem("volume", volume); err != nil {
+		return err
 	}
-	if reverb >= 0 {
-		p.readByte()
+	if change.Balance, err = readItem("balance", balance); err != nil {
+		return err
 	}
-	if phaser >= 0 {
-		p.readByte()
+	if change.Chorus, err = readItem("chorus", chorus); err != nil {
+		return err
 	}
-	if tremolo >= 0 {
-		p.readByte()
+	if change.Reverb, err = readItem("reverb", reverb); err != nil {
+		return err
+	}
+	if change.Phaser, err = readItem("phaser", phaser); err != nil {
+		return err
+	}
+	if change.Tremolo, err = readItem("tremolo", tremolo); err != nil {
+		return err
 	}
 
 	if tempoValue >= 0 {
 		tempo.Value = tempoValue
-		p.skip(1)
-		if p.VersionIndex > 0 {
+		duration, err := p.readByte()
+		if err != nil {
+			return p.fail("mixChange.tempo.duration", err)
+		}
+		change.Tempo = &MixTableItem{Value: tempoValue, Duration: duration}
+		if p.profile().gp4Plus {
 			p.skip(1)
 		}
 	}
 
-	// Read the last byte and skip the next byte
-	p.readByte()
-	p.skip(1)
+	// The applied-to-all bitmask is gp4/gp5 only; gp3's mix change stops
+	// at the tempo fields above.
+	if p.profile().gp4Plus {
+		allTracksFlags, err := p.readByte()
+		if err != nil {
+			return p.fail("mixChange.allTracksFlags", err)
+		}
+		change.AllTracks = allTracksFlags&0x01 != 0
+		change.HideTempo = allTracksFlags&0x02 != 0
+		p.skip(1)
+	}
 
-	// If the version index is greater than 0, read and skip the last two byte-sized strings
-	if p.VersionIndex > 0 {
-		p.readStringByteSizeOfInteger()
-		p.readStringByteSizeOfInteger()
+	// gp4 and gp5 mix changes can also rename the tempo as part of a
+	// transition.
+	if p.profile().gp4Plus {
+		if _, err := p.readStringByteSizeOfInteger(); err != nil {
+			return p.fail("mixChange.tempoNameBefore", err)
+		}
+		if _, err := p.readStringByteSizeOfInteger(); err != nil {
+			return p.fail("mixChange.tempoNameAfter", err)
+		}
 	}
+
+	beat.MixTableChange = &change
+
+	return nil
 }
 
 // readBeatEffects reads the beat effects flags and applies them to the given beat and noteEffect.
-func (p *Parser) readBeatEffects(beat *Beat, noteEffect *NoteEffect) {
+func (p *Parser) readBeatEffects(beat *Beat, noteEffect *NoteEffect) error {
 	flags1, err := p.readUnsignedByte()
 	if err != nil {
-		fmt.Println("Error reading beat effects flags1:", err)
-		return
+		return p.fail("beatEffects.flags1", err)
 	}
 
 	flags2, err := p.readUnsignedByte()
 	if err != nil {
-		fmt.Println("Error reading beat effects flags2:", err)
-		return
+		return p.fail("beatEffects.flags2", err)
 	}
 
 	noteEffect.FadeIn = (flags1 & 0x10) != 0
@@ -824,8 +1109,7 @@ func (p *Parser) readBeatEffects(beat *Beat, noteEffect *NoteEffect) {
 	if (flags1 & 0x20) != 0 {
 		effect, err := p.readUnsignedByte()
 		if err != nil {
-			fmt.Println("Error reading beat effects effect:", err)
-			return
+			return p.fail("beatEffects.effect", err)
 		}
 		noteEffect.Tapping = effect == 1
 		noteEffect.Slapping = effect == 2
@@ -833,20 +1117,20 @@ func (p *Parser) readBeatEffects(beat *Beat, noteEffect *NoteEffect) {
 	}
 
 	if (flags2 & 0x04) != 0 {
-		p.readTremoloBar(noteEffect)
+		if err := p.readTremoloBar(noteEffect); err != nil {
+			return err
+		}
 	}
 
 	if (flags1 & 0x40) != 0 {
 		strokeUp, err := p.readByte()
 		if err != nil {
-			fmt.Println("Error reading beat effects strokeUp:", err)
-			return
+			return p.fail("beatEffects.strokeUp", err)
 		}
 
 		strokeDown, err := p.readByte()
 		if err != nil {
-			fmt.Println("Error reading beat effects strokeDown:", err)
-			return
+			return p.fail("beatEffects.strokeDown", err)
 		}
 
 		// TODO: Implement the correct logic here
@@ -860,44 +1144,56 @@ func (p *Parser) readBeatEffects(beat *Beat, noteEffect *NoteEffect) {
 	}
 
 	if (flags2 & 0x02) != 0 {
-		p.readByte()
+		if _, err := p.readByte(); err != nil {
+			return p.fail("beatEffects.reserved", err)
+		}
 	}
+
+	return nil
 }
 
-// readTremoloBar reads tremolo bar data from the MIDI file and populates the NoteEffect struct.
-// It skips 5 bytes, reads the number of tremolo bar points, and then iterates over each point to populate
-// the TremoloBar and TremoloPoint structs.
-func (p *Parser) readTremoloBar(effect *NoteEffect) {
+// readTremoloBar reads tremolo bar data from the MIDI file and populates the
+// NoteEffect struct. It skips 5 bytes, reads the number of tremolo bar
+// points, and then iterates over each point to populate the TremoloBar and
+// TremoloPoint structs, normalizing each one the same way readBend does.
+func (p *Parser) readTremoloBar(effect *NoteEffect) error {
 	p.skip(5)
 
 	tremoloBar := TremoloBar{}
 	numPoints, err := p.readInt()
 	if err != nil {
-		fmt.Println("Error reading tremolo bar numPoints:", err)
-		return
+		return p.fail("tremoloBar.numPoints", err)
 	}
 
 	for i := 0; i < int(numPoints); i++ {
 		position, err := p.readInt()
 		if err != nil {
-			fmt.Println("Error reading tremolo bar position:", err)
-			return
+			return p.fail(fmt.Sprintf("tremoloBar.point[%d].position", i), err)
 		}
 
 		value, err := p.readInt()
 		if err != nil {
-			fmt.Println("Error reading tremolo bar value:", err)
-			return
+			return p.fail(fmt.Sprintf("tremoloBar.point[%d].value", i), err)
 		}
 
 		p.readByte()
 
-		point := TremoloPoint{}
-		point.Position = int32(math.Round(
-			float64(position) * 1.0 / 1.0)) // TODO: 'max position length' und 'bend position'
+		pos := int32(math.Round(float64(position) *
+			TGEFFECTBEND_MAX_POSITION_LENGTH /
+			float64(GP_BEND_POSITION)))
+		if pos < 0 {
+			pos = 0
+		}
+		if pos > TGEFFECTBEND_MAX_POSITION_LENGTH {
+			pos = TGEFFECTBEND_MAX_POSITION_LENGTH
+		}
 
-		point.Value = int32(math.Round(
-			float64(value) / (1.0 * 0x2f))) // TODO: 'GP_BEND_SEMITONE'
+		point := TremoloPoint{
+			Position: pos,
+			Value: int32(math.Round(float64(value) *
+				TGEFFECTBEND_SEMITONE_LENGTH /
+				float64(GP_BEND_SEMITONE))),
+		}
 
 		tremoloBar.Points = append(tremoloBar.Points, point)
 	}
@@ -905,32 +1201,27 @@ func (p *Parser) readTremoloBar(effect *NoteEffect) {
 	if len(tremoloBar.Points) > 0 {
 		effect.TremoloBar = tremoloBar
 	}
+
+	return nil
 }
 
 // readText reads text from the MIDI file and populates the Text struct in the Beat struct.
-//
-// Parameters:
-// beat: A pointer to the Beat struct where the text will be stored.
-//
-// Returns:
-// This function does not return any value.
-func (p *Parser) readText(beat *Beat) {
+func (p *Parser) readText(beat *Beat) error {
 	text, err := p.readStringByteSizeOfInteger()
 	if err != nil {
-		fmt.Println("Error reading text:", err)
-		return
+		return p.fail("beat.text", err)
 	}
 	beat.Text.Value = text
+	return nil
 }
 
-// readChord reads chord information from the MIDI file and populates the Chord struct in the Beat struct.
-//
-// Parameters:
-// strings: An array of GuitarString representing the strings on which the chord is played.
-// beat: A pointer to the Beat struct where the chord information will be stored.
-//
-// This function does not return any value.
-func (p *Parser) readChord(strings []GuitarString, beat *Beat) {
+// readOldFormatChord reads gp3's fixed-layout chord diagram: a name, a
+// leading fret int that is appended to Frets after its six zeroed slots
+// (so it lands in Frets[6]), then seven fret ints of which the first
+// len(strings) overwrite Frets from index 0. There is no base fret or
+// barre data. gp4 and gp5 fall back to this same layout whenever their
+// newFormat flag is unset.
+func (p *Parser) readOldFormatChord(strings []GuitarString, beat *Beat) error {
 	chord := Chord{
 		Strings: &strings,
 	}
@@ -939,8 +1230,7 @@ func (p *Parser) readChord(strings []GuitarString, beat *Beat) {
 
 	chordName, err := p.readStringByte(21)
 	if err != nil {
-		fmt.Println("Error reading chord name:", err)
-		return
+		return p.fail("oldFormatChord.name", err)
 	}
 
 	chord.Name = chordName
@@ -950,16 +1240,14 @@ func (p *Parser) readChord(strings []GuitarString, beat *Beat) {
 	chord.Frets = make([]int32, 6)
 	chordFrets, err := p.readInt()
 	if err != nil {
-		fmt.Println("Error reading chord fret 0:", err)
-		return
+		return p.fail("oldFormatChord.fret[0]", err)
 	}
 	chord.Frets = append(chord.Frets, chordFrets)
 
 	for i := 0; i < 7; i++ {
 		fret, err := p.readInt()
 		if err != nil {
-			fmt.Printf("Error reading chord fret %d: %v\n", i+1, err)
-			return
+			return p.fail(fmt.Sprintf("oldFormatChord.fret[%d]", i+1), err)
 		}
 		if i < len(strings) {
 			chord.Frets[i] = fret
@@ -971,6 +1259,8 @@ func (p *Parser) readChord(strings []GuitarString, beat *Beat) {
 	if len(strings) > 0 {
 		beat.Chord = chord
 	}
+
+	return nil
 }
 
 // getTime calculates the time duration of a given musical duration.
@@ -1003,12 +1293,11 @@ func (p *Parser) getTime(duration Duration) float64 {
 // Returns:
 //   - float64: The calculated duration value. If an error occurs during reading,
 //     it returns 0.0.
-func (p *Parser) readDuration(flags uint8) float64 {
+func (p *Parser) readDuration(flags uint8) (float64, error) {
 	duration := Duration{}
 	b, err := p.readByte()
 	if err != nil {
-		fmt.Println("Error reading duration flags:", err)
-		return 0.0
+		return 0.0, p.fail("duration.flags", err)
 	}
 	duration.Value = math.Pow(2, float64(b+4)) / 4
 	duration.Dotted = (flags & 0x01) != 0
@@ -1016,8 +1305,7 @@ func (p *Parser) readDuration(flags uint8) float64 {
 	if (flags & 0x20) != 0 {
 		divisionType, err := p.readInt()
 		if err != nil {
-			fmt.Println("Error reading division type:", err)
-			return 0.0
+			return 0.0, p.fail("duration.divisionType", err)
 		}
 		switch divisionType {
 		case 3:
@@ -1054,7 +1342,7 @@ func (p *Parser) readDuration(flags uint8) float64 {
 		duration.Division.Times = 1
 	}
 
-	return p.getTime(duration)
+	return p.getTime(duration), nil
 }
 
 // readBeat reads a beat from the MIDI file and returns its duration.
@@ -1070,11 +1358,10 @@ func (p *Parser) readDuration(flags uint8) float64 {
 // Return:
 //   - float64: The duration of the beat. If the beat is empty (i.e., no notes are present),
 //     the function returns 0.0.
-func (p *Parser) readBeat(start int32, measure *Measure, track *Track, tempo *Tempo, voiceIndex int) float64 {
+func (p *Parser) readBeat(start int32, measure *Measure, track *Track, tempo *Tempo, voiceIndex int) (float64, error) {
 	flags, err := p.readUnsignedByte()
 	if err != nil {
-		fmt.Println("Error reading beat flags:", err)
-		return 0.0
+		return 0.0, p.fail("beat.flags", err)
 	}
 
 	beat := p.getBeat(measure, start)
@@ -1083,39 +1370,59 @@ func (p *Parser) readBeat(start int32, measure *Measure, track *Track, tempo *Te
 	if (flags & 0x40) != 0 {
 		beatType, err := p.readUnsignedByte()
 		if err != nil {
-			fmt.Println("Error reading beat type:", err)
-			return 0.0
+			return 0.0, p.fail("beat.type", err)
 		}
 
 		voice.Empty = (beatType & 0x02) == 0
 	}
 
-	duration := p.readDuration(flags)
+	duration, err := p.readDuration(flags)
+	if err != nil {
+		return 0.0, err
+	}
 	effect := NoteEffect{}
 
 	if (flags & 0x02) != 0 {
-		p.readChord(track.GuitarStrings, beat)
+		if err := p.readChord(track.GuitarStrings, beat); err != nil {
+			return 0.0, err
+		}
 	}
 	if (flags & 0x04) != 0 {
-		p.readText(beat)
+		if err := p.readText(beat); err != nil {
+			return 0.0, err
+		}
 	}
 	if (flags & 0x08) != 0 {
-		p.readBeatEffects(beat, &effect)
+		if err := p.readBeatEffects(beat, &effect); err != nil {
+			return 0.0, err
+		}
 	}
 	if (flags & 0x10) != 0 {
-		p.readMixChange(tempo)
+		if err := p.readMixChange(beat, tempo, track.ChannelID); err != nil {
+			return 0.0, err
+		}
+		if beat.MixTableChange != nil {
+			if err := p.fireMixChange(*beat.MixTableChange); err != nil {
+				return 0.0, err
+			}
+		}
 	}
 
 	stringFlags, err := p.readUnsignedByte()
 	if err != nil {
-		fmt.Println("Error reading string flags:", err)
-		return 0.0
+		return 0.0, p.fail("beat.stringFlags", err)
 	}
 
 	for i := 6; i >= 0; i-- {
 		if stringFlags&(1<<i) != 0 && (6-i) < len(track.GuitarStrings) {
 			string := track.GuitarStrings[6-i]
-			note := p.readNote(string, track, effect)
+			note, err := p.readNote(string, effect)
+			if err != nil {
+				return 0.0, err
+			}
+			if err := p.fireNote(note); err != nil {
+				return 0.0, err
+			}
 			voice.Notes = append(voice.Notes, note)
 		}
 	}
@@ -1125,18 +1432,21 @@ func (p *Parser) readBeat(start int32, measure *Measure, track *Track, tempo *Te
 
 	read, err := p.readByte()
 	if err != nil {
-		fmt.Println("Error reading note flags:", err)
-		return 0.0
+		return 0.0, p.fail("beat.noteFlags", err)
 	}
 
 	if (read & 0x02) != 0 {
 		p.skip(1)
 	}
 
+	if err := p.fireBeat(track.Number, measure.Header.Number, *beat); err != nil {
+		return 0.0, err
+	}
+
 	if len(voice.Notes) != 0 {
-		return duration
+		return duration, nil
 	}
-	return 0.0
+	return 0.0, nil
 }
 
 // readNote reads and processes a note from the MIDI file.
@@ -1145,11 +1455,10 @@ func (p *Parser) readBeat(start int32, measure *Measure, track *Track, tempo *Te
 // If the note is not tied, it sets the fret value to the value read from the MIDI file.
 // If the fret value is invalid, it sets it to 0.
 // The function also skips over any additional data related to the note.
-func (p *Parser) readNote(guitarString GuitarString, track *Track, effect NoteEffect) Note {
+func (p *Parser) readNote(guitarString GuitarString, effect NoteEffect) (Note, error) {
 	flags, err := p.readUnsignedByte()
 	if err != nil {
-		fmt.Println("Error reading note flags:", err)
-		return Note{}
+		return Note{}, p.fail("note.flags", err)
 	}
 
 	note := Note{
@@ -1163,8 +1472,7 @@ func (p *Parser) readNote(guitarString GuitarString, track *Track, effect NoteEf
 	if (flags & 0x20) != 0 {
 		noteType, err := p.readUnsignedByte()
 		if err != nil {
-			fmt.Println("Error reading note type:", err)
-			return Note{}
+			return Note{}, p.fail("note.type", err)
 		}
 
 		note.TiedNote = noteType == 0x02
@@ -1174,8 +1482,7 @@ func (p *Parser) readNote(guitarString GuitarString, track *Track, effect NoteEf
 	if (flags & 0x10) != 0 {
 		velocity, err := p.readByte()
 		if err != nil {
-			fmt.Println("Error reading velocity:", err)
-			return Note{}
+			return Note{}, p.fail("note.velocity", err)
 		}
 
 		note.Velocity = TGVELOCITIES_MIN_VELOCITY +
@@ -1186,18 +1493,18 @@ func (p *Parser) readNote(guitarString GuitarString, track *Track, effect NoteEf
 	if (flags & 0x20) != 0 {
 		fret, err := p.readByte()
 		if err != nil {
-			fmt.Println("Error reading fret:", err)
-			return Note{}
+			return Note{}, p.fail("note.fret", err)
 		}
 		value := fret
 		if note.TiedNote {
-			value = p.getTiedNoteValue(guitarString.Number, track)
+			value = p.getTiedNoteValue(guitarString.Number)
 		}
 		if value >= 0 && value < 100 {
 			note.Value = value
 		} else {
 			note.Value = 0
 		}
+		p.setTiedNoteValue(guitarString.Number, note.Value)
 	}
 	if (flags & 0x80) != 0 {
 		p.skip(2)
@@ -1207,40 +1514,45 @@ func (p *Parser) readNote(guitarString GuitarString, track *Track, effect NoteEf
 	}
 	p.skip(1)
 	if (flags & 0x08) != 0 {
-		p.readNoteEffects(&note.Effect)
+		if err := p.readNoteEffects(&note.Effect); err != nil {
+			return Note{}, err
+		}
+		if err := p.fireNoteEffect(note.Effect); err != nil {
+			return Note{}, err
+		}
 	}
 
-	return note
+	return note, nil
 }
 
-// getTiedNoteValue retrieves the fret value of the last tied note on the same string in the previous measures.
-// It iterates through the measures and beats in reverse order, looking for the last tied note on the specified guitar string.
-// If a tied note is found, its fret value is returned. If no tied note is found, 0 is returned.
-func (p *Parser) getTiedNoteValue(guitarString int32, track *Track) uint8 {
-	measureCount := len(track.Measures)
-	if measureCount > 0 {
-		for m := measureCount - 1; m >= 0; m-- {
-			measure := track.Measures[m]
-			for b := len(measure.Beats) - 1; b >= 0; b-- {
-				beat := measure.Beats[b]
-				for v := 0; v < len(beat.Voices); v++ {
-					voice := beat.Voices[v]
-					if !voice.Empty {
-						for n := len(voice.Notes) - 1; n >= 0; n-- {
-							note := voice.Notes[n]
-							if note.String == guitarString && note.TiedNote {
-								return note.Value
-							}
-						}
-					}
-				}
-			}
-		}
+// getTiedNoteValue returns the fret value of the most recent note readNote
+// saw on guitarString within the current track, via tiedNoteCache.
+// If none has been seen yet, it returns 0, the same default the old
+// backward scan over track.Measures returned when it found nothing.
+func (p *Parser) getTiedNoteValue(guitarString int32) uint8 {
+	idx := guitarString - 1
+	if idx < 0 || int(idx) >= len(p.tiedNoteCache) {
+		return 0
+	}
+	if cached := p.tiedNoteCache[idx]; cached.Set {
+		return cached.Value
 	}
-
 	return 0
 }
 
+// setTiedNoteValue records value as the most recent note seen on
+// guitarString, for later getTiedNoteValue calls within the same track.
+func (p *Parser) setTiedNoteValue(guitarString int32, value uint8) {
+	idx := guitarString - 1
+	if idx < 0 || int(idx) >= len(p.tiedNoteCache) {
+		return
+	}
+	p.tiedNoteCache[idx] = struct {
+		Value uint8
+		Set   bool
+	}{Value: value, Set: true}
+}
+
 // readNoteEffects reads and processes note effects from the MIDI file.
 // It extracts relevant information such as bend, grace notes, tremolo picking, slide, artificial harmonics, trills, hammer, let ring, vibrato, palm mute, and staccato.
 //
@@ -1248,79 +1560,121 @@ func (p *Parser) getTiedNoteValue(guitarString int32, track *Track) uint8 {
 // - noteEffect: A pointer to a NoteEffect struct where the extracted note effects will be stored.
 //
 // The function reads the note effect flags from the MIDI file and calls the appropriate functions to process each type of note effect.
-// It also sets the corresponding fields in the NoteEffect struct based on the extracted information.
-func (p *Parser) readNoteEffects(noteEffect *NoteEffect) {
+// It also sets the corresponding fields in the NoteEffect struct based on the extracted information. In Strict mode it
+// returns the first read error it hits; otherwise it records a ParseWarning via fail and carries on.
+func (p *Parser) readNoteEffects(noteEffect *NoteEffect) error {
 	flags1, err := p.readUnsignedByte()
 	if err != nil {
-		fmt.Println("Error reading note effect flags 1:", err)
-		return
+		return p.fail("noteEffect.flags1", err)
 	}
 
 	flags2, err := p.readUnsignedByte()
 	if err != nil {
-		fmt.Println("Error reading note effect flags 2:", err)
-		return
+		return p.fail("noteEffect.flags2", err)
 	}
 
 	if (flags1 & 0x01) != 0 {
-		p.readBend(noteEffect)
+		if err := p.readBend(noteEffect); err != nil {
+			return err
+		}
 	}
 	if (flags1 & 0x10) != 0 {
-		p.readGrace(noteEffect)
+		if err := p.readGrace(noteEffect); err != nil {
+			return err
+		}
 	}
 	if (flags2 & 0x04) != 0 {
-		p.readTremoloPicking(noteEffect)
+		if err := p.readTremoloPicking(noteEffect); err != nil {
+			return err
+		}
 	}
 	if (flags2 & 0x08) != 0 {
 		noteEffect.Slide = true
-		p.readByte() // Assume it's a placeholder for additional data related to slide
+		if _, err := p.readByte(); err != nil { // placeholder for additional data related to slide
+			return p.fail("noteEffect.slide", err)
+		}
 	}
 	if (flags2 & 0x10) != 0 {
-		p.readArtificialHarmonic(noteEffect)
+		if err := p.readArtificialHarmonic(noteEffect); err != nil {
+			return err
+		}
 	}
 	if (flags2 & 0x20) != 0 {
-		p.readTrill(noteEffect)
+		if err := p.readTrill(noteEffect); err != nil {
+			return err
+		}
 	}
 	noteEffect.Hammer = (flags1 & 0x02) != 0
 	noteEffect.LetRing = (flags1 & 0x08) != 0
 	noteEffect.Vibrato = (flags2 & 0x40) != 0
 	noteEffect.PalmMute = (flags2 & 0x02) != 0
 	noteEffect.Staccato = (flags2 & 0x01) != 0
+
+	if p.profile().hasFingering && (flags2&0x80) != 0 {
+		if err := p.readFingering(noteEffect); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// readFingering reads gp5's left-hand/right-hand fingering pair, a pair of
+// signed bytes appended to the note effect when flags2's fingering bit is
+// set. gp3/gp4 never set that bit, so this is only ever called for gp5.
+func (p *Parser) readFingering(noteEffect *NoteEffect) error {
+	left, err := p.readByte()
+	if err != nil {
+		return p.fail("noteEffect.leftHandFingering", err)
+	}
+	right, err := p.readByte()
+	if err != nil {
+		return p.fail("noteEffect.rightHandFingering", err)
+	}
+	noteEffect.LeftHandFingering = int8(left)
+	noteEffect.RightHandFingering = int8(right)
+	return nil
 }
 
 // readBend reads and processes bend information from the MIDI file.
 // It extracts relevant information such as bend points and their positions and values.
-// The function skips over unnecessary data and handles potential errors.
 // If the bend points are successfully extracted, they are stored in a Bend struct and assigned to the NoteEffect.
-func (p *Parser) readBend(effect *NoteEffect) {
+func (p *Parser) readBend(effect *NoteEffect) error {
 	p.skip(5) // Skip over unnecessary data
 
 	bend := Bend{}
 
 	numPoints, err := p.readInt()
 	if err != nil {
-		fmt.Println("Error reading bend points count:", err)
-		return
+		return p.fail("bend.numPoints", err)
 	}
 
 	for i := 0; i < int(numPoints); i++ {
 		bendPosition, err := p.readInt()
 		if err != nil {
-			fmt.Println("Error reading bend point position:", err)
-			return
+			return p.fail(fmt.Sprintf("bend.point[%d].position", i), err)
 		}
 
 		bendValue, err := p.readInt()
 		if err != nil {
-			fmt.Println("Error reading bend point value:", err)
-			return
+			return p.fail(fmt.Sprintf("bend.point[%d].value", i), err)
+		}
+		if _, err := p.readByte(); err != nil { // padding
+			return p.fail(fmt.Sprintf("bend.point[%d].padding", i), err)
+		}
+
+		pos := int32(math.Round(float64(bendPosition) *
+			TGEFFECTBEND_MAX_POSITION_LENGTH /
+			float64(GP_BEND_POSITION)))
+		if pos < 0 {
+			pos = 0
+		}
+		if pos > TGEFFECTBEND_MAX_POSITION_LENGTH {
+			pos = TGEFFECTBEND_MAX_POSITION_LENGTH
 		}
-		p.readByte() // Skip over padding or unused field
 
 		point := BendPoint{
-			Position: int32(math.Round(float64(bendPosition) *
-				TGEFFECTBEND_MAX_POSITION_LENGTH /
-				float64(GP_BEND_POSITION))),
+			Position: pos,
 			Value: int32(math.Round(float64(bendValue) *
 				TGEFFECTBEND_SEMITONE_LENGTH /
 				float64(GP_BEND_SEMITONE))),
@@ -1331,41 +1685,36 @@ func (p *Parser) readBend(effect *NoteEffect) {
 	if len(bend.Points) > 0 {
 		effect.Bend = bend
 	}
+	return nil
 }
 
 // readGrace reads and processes grace note information from the MIDI file.
 // It extracts relevant information such as fret, dynamic, transition, duration, and flags.
-// The function handles potential errors during the reading process.
 // If the grace note information is successfully extracted, it is stored in a Grace struct and assigned to the NoteEffect.
-func (p *Parser) readGrace(effect *NoteEffect) {
+func (p *Parser) readGrace(effect *NoteEffect) error {
 	fret, err := p.readUnsignedByte()
 	if err != nil {
-		fmt.Println("Error reading grace fret:", err)
-		return
+		return p.fail("grace.fret", err)
 	}
 
 	dynamic, err := p.readUnsignedByte()
 	if err != nil {
-		fmt.Println("Error reading grace dynamic:", err)
-		return
+		return p.fail("grace.dynamic", err)
 	}
 
 	transition, err := p.readByte()
 	if err != nil {
-		fmt.Println("Error reading grace transition:", err)
-		return
+		return p.fail("grace.transition", err)
 	}
 
 	duration, err := p.readUnsignedByte()
 	if err != nil {
-		fmt.Println("Error reading grace duration:", err)
-		return
+		return p.fail("grace.duration", err)
 	}
 
 	flags, err := p.readUnsignedByte()
 	if err != nil {
-		fmt.Println("Error reading grace flags:", err)
-		return
+		return p.fail("grace.flags", err)
 	}
 
 	grace := Grace{
@@ -1390,21 +1739,17 @@ func (p *Parser) readGrace(effect *NoteEffect) {
 	}
 
 	effect.Grace = grace
+	return nil
 }
 
 // readTremoloPicking reads and processes tremolo picking information from the MIDI file.
-// It extracts relevant information such as the duration of the tremolo picking.
-//
-// Parameters:
-// - effect: A pointer to a NoteEffect struct where the extracted tremolo picking information will be stored.
-//
-// The function reads the tremolo picking value from the MIDI file and sets the corresponding duration value in the TremoloPicking struct.
-// If an error occurs during the reading process, an error message is printed and the function returns without any further action.
-func (p *Parser) readTremoloPicking(effect *NoteEffect) {
+// It extracts relevant information such as the duration of the tremolo picking, and
+// sets the corresponding duration value in the TremoloPicking struct. An unrecognised
+// value leaves the NoteEffect unchanged rather than being treated as a read error.
+func (p *Parser) readTremoloPicking(effect *NoteEffect) error {
 	value, err := p.readUnsignedByte()
 	if err != nil {
-		fmt.Println("Error reading tremolo picking value:", err)
-		return
+		return p.fail("tremoloPicking.value", err)
 	}
 
 	tp := TremoloPicking{}
@@ -1417,25 +1762,21 @@ func (p *Parser) readTremoloPicking(effect *NoteEffect) {
 	case 3:
 		tp.Duration.Value = "thirty_second"
 	default:
-		return // No valid value, so no action is taken
+		return nil // No valid value, so no action is taken
 	}
 
 	effect.TremoloPicking = tp
+	return nil
 }
 
 // readArtificialHarmonic reads and processes artificial harmonic information from the MIDI file.
-// It extracts relevant information such as the type of artificial harmonic.
-//
-// Parameters:
-// - effect: A pointer to a NoteEffect struct where the extracted artificial harmonic information will be stored.
-//
-// The function reads the artificial harmonic type value from the MIDI file and sets the corresponding type value in the Harmonic struct.
-// If an error occurs during the reading process, an error message is printed and the function returns without any further action.
-func (p *Parser) readArtificialHarmonic(effect *NoteEffect) {
+// It extracts relevant information such as the type of artificial harmonic, and sets the
+// corresponding type value in the Harmonic struct. An unrecognised type leaves the
+// NoteEffect unchanged rather than being treated as a read error.
+func (p *Parser) readArtificialHarmonic(effect *NoteEffect) error {
 	typeVal, err := p.readByte()
 	if err != nil {
-		fmt.Println("Error reading artificial harmonic type:", err)
-		return
+		return p.fail("harmonic.type", err)
 	}
 
 	harmonic := Harmonic{}
@@ -1454,32 +1795,26 @@ func (p *Parser) readArtificialHarmonic(effect *NoteEffect) {
 	case 5:
 		harmonic.Type = "semi"
 	default:
-		return // Bei unbekanntem Typ keine Änderung
+		return nil // Bei unbekanntem Typ keine Änderung
 	}
 
 	effect.Harmonic = harmonic
+	return nil
 }
 
-// readTrill reads and processes trill information from the MIDI file.
-//
-// Parameters:
-// - effect: A pointer to a NoteEffect struct where the extracted trill information will be stored.
-//
-// The function reads the trill fret and period values from the MIDI file.
-// If an error occurs during the reading process, an error message is printed and the function returns without any further action.
-// The trill information is then stored in a Trill struct and assigned to the NoteEffect.
-// If the period value is not recognized, no changes are made to the trill information.
-func (p *Parser) readTrill(effect *NoteEffect) {
+// readTrill reads and processes trill information from the MIDI file: the trill fret
+// and period values. The trill information is then stored in a Trill struct and
+// assigned to the NoteEffect. An unrecognised period leaves the NoteEffect unchanged
+// rather than being treated as a read error.
+func (p *Parser) readTrill(effect *NoteEffect) error {
 	fret, err := p.readByte()
 	if err != nil {
-		fmt.Println("Error reading trill fret:", err)
-		return
+		return p.fail("trill.fret", err)
 	}
 
 	period, err := p.readByte()
 	if err != nil {
-		fmt.Println("Error reading trill period:", err)
-		return
+		return p.fail("trill.period", err)
 	}
 
 	trill := Trill{
@@ -1494,10 +1829,11 @@ func (p *Parser) readTrill(effect *NoteEffect) {
 	case 3:
 		trill.Duration.Value = "sixty_fourth"
 	default:
-		return // Bei unbekanntem period keine Änderung
+		return nil // Bei unbekanntem period keine Änderung
 	}
 
 	effect.Trill = trill
+	return nil
 }
 
 // isPercussionChannel checks if the given MIDI channel ID corresponds to a percussion channel.
@@ -1509,12 +1845,17 @@ func (p *Parser) readTrill(effect *NoteEffect) {
 //   - bool: A boolean value indicating whether the given channel ID corresponds to a percussion channel.
 //     Returns true if the channel ID is found in the list of percussion channels, otherwise returns false.
 func (p *Parser) isPercussionChannel(channelId int32) bool {
+	return p.percussionChannels[channelId]
+}
+
+// indexPercussionChannels builds percussionChannels from Channels. Parse
+// calls it once, right after Channels is populated, so isPercussionChannel
+// is an O(1) map lookup instead of a linear scan on every call.
+func (p *Parser) indexPercussionChannels() {
+	p.percussionChannels = make(map[int32]bool, len(p.Channels))
 	for _, channel := range p.Channels {
-		if channel.ID == channelId {
-			return channel.IsPercussionChannel
-		}
+		p.percussionChannels[channel.ID] = channel.IsPercussionChannel
 	}
-	return false
 }
 
 // getClef retrieves the clef type for the given track based on the guitar strings' values.
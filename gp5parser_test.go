@@ -0,0 +1,67 @@
+package parsegp
+
+import (
+	"bufio"
+	"bytes"
+	"testing"
+)
+
+// newTestParser returns a Parser reading directly from data, bypassing
+// NewParser's version-header handshake, for tests that only need the
+// byte-level read helpers.
+func newTestParser(data []byte) *Parser {
+	return &Parser{br: bufio.NewReader(bytes.NewReader(data))}
+}
+
+func TestTiedNoteCacheRoundTrips(t *testing.T) {
+	p := newTestParser(nil)
+
+	if got := p.getTiedNoteValue(1); got != 0 {
+		t.Fatalf("getTiedNoteValue before any set = %d, want 0", got)
+	}
+
+	p.setTiedNoteValue(3, 12)
+	if got := p.getTiedNoteValue(3); got != 12 {
+		t.Fatalf("getTiedNoteValue(3) = %d, want 12", got)
+	}
+	if got := p.getTiedNoteValue(1); got != 0 {
+		t.Fatalf("getTiedNoteValue(1) = %d, want 0 (unaffected by string 3's set)", got)
+	}
+
+	p.setTiedNoteValue(3, 7)
+	if got := p.getTiedNoteValue(3); got != 7 {
+		t.Fatalf("getTiedNoteValue(3) after overwrite = %d, want 7", got)
+	}
+}
+
+func TestTiedNoteCacheIgnoresOutOfRangeString(t *testing.T) {
+	p := newTestParser(nil)
+
+	p.setTiedNoteValue(0, 5)
+	p.setTiedNoteValue(8, 5)
+	if got := p.getTiedNoteValue(0); got != 0 {
+		t.Fatalf("getTiedNoteValue(0) = %d, want 0 (out of range, never cached)", got)
+	}
+	if got := p.getTiedNoteValue(8); got != 0 {
+		t.Fatalf("getTiedNoteValue(8) = %d, want 0 (out of range, never cached)", got)
+	}
+}
+
+func TestIndexPercussionChannels(t *testing.T) {
+	p := newTestParser(nil)
+	p.Channels = []Channel{
+		{ID: 9, IsPercussionChannel: true},
+		{ID: 0, IsPercussionChannel: false},
+	}
+	p.indexPercussionChannels()
+
+	if !p.isPercussionChannel(9) {
+		t.Fatalf("isPercussionChannel(9) = false, want true")
+	}
+	if p.isPercussionChannel(0) {
+		t.Fatalf("isPercussionChannel(0) = true, want false")
+	}
+	if p.isPercussionChannel(99) {
+		t.Fatalf("isPercussionChannel(99) = true, want false for an unknown channel")
+	}
+}
@@ -0,0 +1,61 @@
+package parsegp
+
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+)
+
+// tremoloBarChunk encodes a readTremoloBar chunk the way a gp5 file does:
+// five skipped bytes and a 32-bit little-endian point count, then for each
+// point a 32-bit little-endian position and value and a padding byte.
+func tremoloBarChunk(points [][2]int32) []byte {
+	var buf bytes.Buffer
+	buf.Write(make([]byte, 5)) // readTremoloBar's leading skip(5)
+	_ = binary.Write(&buf, binary.LittleEndian, int32(len(points)))
+	for _, pt := range points {
+		_ = binary.Write(&buf, binary.LittleEndian, pt[0])
+		_ = binary.Write(&buf, binary.LittleEndian, pt[1])
+		buf.WriteByte(0)
+	}
+	return buf.Bytes()
+}
+
+func TestReadTremoloBarNormalizesPositionAndValue(t *testing.T) {
+	// position=GP_BEND_POSITION (60) should normalize to the max position
+	// length (12); value=GP_BEND_SEMITONE (25) should normalize to one
+	// full semitone (TGEFFECTBEND_SEMITONE_LENGTH, 1).
+	p := newTestParser(tremoloBarChunk([][2]int32{{GP_BEND_POSITION, GP_BEND_SEMITONE}}))
+
+	var effect NoteEffect
+	if err := p.readTremoloBar(&effect); err != nil {
+		t.Fatalf("readTremoloBar: %v", err)
+	}
+
+	if len(effect.TremoloBar.Points) != 1 {
+		t.Fatalf("got %d points, want 1", len(effect.TremoloBar.Points))
+	}
+	got := effect.TremoloBar.Points[0]
+	if got.Position != TGEFFECTBEND_MAX_POSITION_LENGTH {
+		t.Errorf("Position = %d, want %d", got.Position, TGEFFECTBEND_MAX_POSITION_LENGTH)
+	}
+	if got.Value != TGEFFECTBEND_SEMITONE_LENGTH {
+		t.Errorf("Value = %d, want %d", got.Value, TGEFFECTBEND_SEMITONE_LENGTH)
+	}
+}
+
+func TestReadTremoloBarClampsOutOfRangePosition(t *testing.T) {
+	// A position well past GP_BEND_POSITION must clamp to the max position
+	// length rather than overflowing it.
+	p := newTestParser(tremoloBarChunk([][2]int32{{GP_BEND_POSITION * 4, 0}}))
+
+	var effect NoteEffect
+	if err := p.readTremoloBar(&effect); err != nil {
+		t.Fatalf("readTremoloBar: %v", err)
+	}
+
+	got := effect.TremoloBar.Points[0].Position
+	if got != TGEFFECTBEND_MAX_POSITION_LENGTH {
+		t.Fatalf("Position = %d, want clamped to %d", got, TGEFFECTBEND_MAX_POSITION_LENGTH)
+	}
+}
@@ -0,0 +1,245 @@
+// Description: This file extends Writer (songwriter.go) to also target
+// gp5 (VERSIONS index 4, v5.10): the lyrics track, directions/RSE master
+// effect, per-track RSE instrument block and fingering that profile.
+// hasDirections/hasFingering gate on the read side. WriteGP5Song is the
+// gp5 counterpart of WriteSong; Parser.WriteGP5 is the convenience method
+// that serializes whatever a Parser just parsed. NewBuilder gives callers
+// a fluent way to construct a TabFile from scratch - for transposition,
+// tempo/key-signature edits or generating a tab programmatically - without
+// hand-building the struct literal themselves.
+
+package parsegp
+
+import (
+	"errors"
+	"io"
+	"strings"
+)
+
+// writeShort is the inverse of Parser.readShort: a little-endian signed
+// 16-bit integer.
+func (w *Writer) writeShort(v int16) {
+	w.buf.WriteByte(byte(v))
+	w.buf.WriteByte(byte(v >> 8))
+}
+
+// WriteGP5Song serializes tab as a gp5 (v5.10) file and returns its bytes,
+// the gp5 counterpart of WriteSong. Page setup, measure headers, channels,
+// tracks, measures, beats and notes are written with the same writeX
+// methods WriteSong uses - profileFor(4)'s hasDirections/voiceCount/
+// hasFingering already make them emit the gp5 layout - plus the
+// gp5-only lyrics track, directions block, RSE master effect and
+// per-track RSE instrument block this function adds around them.
+func WriteGP5Song(tab *TabFile) ([]byte, error) {
+	w := NewWriter()
+	w.VersionIndex = len(VERSIONS) - 1 // v5.10
+	profile := w.profile()
+
+	w.writeStringByte(VERSIONS[w.VersionIndex], 30)
+
+	w.writeStringByteSizeOfInteger(tab.Title)
+	w.writeStringByteSizeOfInteger(tab.Subtitle)
+	w.writeStringByteSizeOfInteger(tab.Artist)
+	w.writeStringByteSizeOfInteger(tab.Album)
+	w.writeStringByteSizeOfInteger(tab.LyricsAuthor)
+	w.writeStringByteSizeOfInteger(tab.MusicAuthor)
+	w.writeStringByteSizeOfInteger(tab.Copyright)
+	w.writeStringByteSizeOfInteger(tab.Tab)
+	w.writeStringByteSizeOfInteger(tab.Instructions)
+
+	var comments []string
+	if tab.Comments != "" {
+		comments = strings.Split(tab.Comments, "\n")
+	}
+	w.writeInt(int32(len(comments)))
+	for _, comment := range comments {
+		w.writeStringInteger(comment)
+	}
+
+	w.writeLyrics(tab.Lyric)
+
+	w.writeInt(int32(tab.TempoValue))
+	w.writeByte(0) // humanize tempo: not modeled, left off
+
+	w.writeByte(byte(tab.GlobalKeySignature - 7)) // inverse of readKeySignature
+	w.writeBytes(1)
+
+	w.writePageSetup(profile)
+	w.writeDirections(tab)
+	w.writeRSEMasterEffect(tab.MasterReverb)
+
+	w.writeChannels(tab.Channels)
+
+	w.writeInt(int32(len(tab.MeasureHeaders)))
+	w.writeInt(int32(len(tab.Tracks)))
+
+	w.writeMeasureHeaders(tab.MeasureHeaders)
+	w.writeGP5Tracks(tab.Tracks)
+
+	for _, track := range tab.Tracks {
+		for mi := range tab.MeasureHeaders {
+			var measure Measure
+			if mi < len(track.Measures) {
+				measure = track.Measures[mi]
+			}
+			w.writeMeasure(measure, profile)
+		}
+	}
+
+	return w.buf.Bytes(), nil
+}
+
+// writeLyrics is the inverse of Parser.readLyrics: the lead track's lyric
+// line, followed by the 4 other tracks' worth of lyric data readLyrics
+// also consumes but TabFile does not retain, written out empty.
+func (w *Writer) writeLyrics(lyric Lyric) {
+	w.writeInt(int32(lyric.From))
+	w.writeStringInteger(lyric.Lyric)
+	for i := 0; i < 4; i++ {
+		w.writeInt(0)
+		w.writeStringInteger("")
+	}
+}
+
+// writeDirections is the inverse of Parser.readDirections: the tempo name,
+// hide-tempo flag, key/octave and the 19 named direction markers, in the
+// fixed order directionNames lists them.
+func (w *Writer) writeDirections(tab *TabFile) {
+	w.writeStringByteSizeOfInteger(tab.TempoName)
+
+	var hideTempo byte
+	if tab.HideTempo {
+		hideTempo = 1
+	}
+	w.writeByte(hideTempo)
+
+	w.writeByte(byte(tab.Key))
+	w.writeByte(byte(tab.Octave))
+
+	for _, name := range directionNames {
+		w.writeShort(tab.Directions[name])
+	}
+}
+
+// writeRSEMasterEffect is the inverse of Parser.readRSEMasterEffect. The
+// equalizer bands it reads are not modeled on TabFile, so they are written
+// as zero, the same way writePageSetup already does for its own fields.
+func (w *Writer) writeRSEMasterEffect(masterVolume int32) {
+	w.writeInt(masterVolume)
+	w.writeBytes(4)  // reserved
+	w.writeBytes(11) // equalizer: 10 bands plus overall gain
+}
+
+// writeGP5Tracks is writeTracks's body with the RSE instrument block
+// Parser.readTracks appends to each track when profile.hasDirections
+// interleaved after it, rather than after all tracks.
+func (w *Writer) writeGP5Tracks(tracks []Track) {
+	for _, track := range tracks {
+		w.writeByte(0) // track flags: not modeled
+
+		w.writeStringByte(track.Name, 40)
+
+		w.writeInt(int32(len(track.GuitarStrings)))
+		for s := 0; s < 7; s++ {
+			var tuning int32
+			for _, gs := range track.GuitarStrings {
+				if int(gs.Number) == s+1 {
+					tuning = gs.Value
+				}
+			}
+			w.writeInt(tuning)
+		}
+
+		w.writeBytes(4) // port
+		w.writeTrackChannel(track)
+		w.writeBytes(4) // fret count
+		w.writeInt(0)   // capo: not modeled
+
+		w.writeByte(0) // color.r
+		w.writeByte(0) // color.g
+		w.writeByte(0) // color.b
+		w.writeBytes(1)
+
+		w.writeBytes(13) // RSE instrument block: not modeled
+	}
+}
+
+// WriteGP5 serializes p.TabFile - the result of the most recent Parse -
+// as a gp5 (v5.10) file to w. Callers that only have a TabFile, not a
+// Parser, should call WriteGP5Song directly instead.
+func (p *Parser) WriteGP5(w io.Writer) error {
+	if p.TabFile == nil {
+		return errors.New("parsegp: WriteGP5 called before Parse produced a TabFile")
+	}
+	data, err := WriteGP5Song(p.TabFile)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(data)
+	return err
+}
+
+// Builder is a fluent TabFile constructor for programmatic tab generation:
+// transposing a song, editing its tempo or key signature, or building one
+// from scratch, without hand-assembling the TabFile struct literal.
+type Builder struct {
+	tab *TabFile
+}
+
+// NewBuilder returns a Builder seeded with a v5.10 TabFile - WriteGP5Song's
+// version - and a 120bpm tempo default so AddTrack/AddMeasureHeader callers
+// don't have to set up boilerplate they don't care about. It sets no time
+// signature; that is carried by each MeasureHeader.
+func NewBuilder() *Builder {
+	return &Builder{tab: &TabFile{Major: 5, Minor: 10, TempoValue: 120}}
+}
+
+func (b *Builder) Title(title string) *Builder {
+	b.tab.Title = title
+	return b
+}
+
+func (b *Builder) Artist(artist string) *Builder {
+	b.tab.Artist = artist
+	return b
+}
+
+func (b *Builder) Tempo(bpm int) *Builder {
+	b.tab.TempoValue = bpm
+	return b
+}
+
+func (b *Builder) KeySignature(keySignature int) *Builder {
+	b.tab.GlobalKeySignature = keySignature
+	return b
+}
+
+func (b *Builder) AddChannel(channel Channel) *Builder {
+	b.tab.Channels = append(b.tab.Channels, channel)
+	return b
+}
+
+func (b *Builder) AddMeasureHeader(header MeasureHeader) *Builder {
+	b.tab.MeasureHeaders = append(b.tab.MeasureHeaders, header)
+	return b
+}
+
+func (b *Builder) AddTrack(track Track) *Builder {
+	b.tab.Tracks = append(b.tab.Tracks, track)
+	return b
+}
+
+// Build returns the TabFile assembled so far.
+func (b *Builder) Build() *TabFile {
+	return b.tab
+}
+
+// WriteGP5 builds and serializes the TabFile to w in one step.
+func (b *Builder) WriteGP5(w io.Writer) error {
+	data, err := WriteGP5Song(b.tab)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(data)
+	return err
+}
@@ -0,0 +1,90 @@
+package parsegp
+
+import (
+	"archive/zip"
+	"fmt"
+	"io"
+	"os"
+)
+
+// loadGPFile loads a Guitar Pro 7/8 file: a plain ZIP archive whose
+// Content/score.gpif entry is the same GPIF XML that loadGPXFile decodes
+// out of a BCFS container. It is the .gp sibling of loadGPXFile; dispatch
+// between the two (and the gp3-gp5 path) happens in LoadHeader based on
+// the file's magic bytes.
+//
+// Embedded binaries such as BinaryStylesheet or bundled audio tracks are
+// surfaced as raw byte slices rather than decoded, since nothing in GPFile
+// models them yet.
+func (gp *GPFile) loadGPFile() error {
+	fi, err := os.Stat(gp.FullPath)
+	if err != nil || fi.Size() == 0 {
+		return fmt.Errorf("file is empty or does not exist")
+	}
+
+	f, err := os.Open(gp.FullPath)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	sr := io.NewSectionReader(f, 0, fi.Size())
+	zr, err := zip.NewReader(sr, fi.Size())
+	if err != nil {
+		return fmt.Errorf("gp: not a zip archive: %w", err)
+	}
+
+	var gpif *zip.File
+	gp.Binaries = make(map[string][]byte)
+
+	for _, entry := range zr.File {
+		switch entry.Name {
+		case "Content/score.gpif":
+			gpif = entry
+		case "BinaryStylesheet":
+			data, err := readZipEntry(entry)
+			if err != nil {
+				return err
+			}
+			gp.Binaries[entry.Name] = data
+		default:
+			if isAudioEntry(entry.Name) {
+				data, err := readZipEntry(entry)
+				if err != nil {
+					return err
+				}
+				gp.Binaries[entry.Name] = data
+			}
+		}
+	}
+
+	if gpif == nil {
+		return fmt.Errorf("gp: archive has no Content/score.gpif entry")
+	}
+
+	rc, err := gpif.Open()
+	if err != nil {
+		return err
+	}
+	defer rc.Close()
+
+	return gp.loadGPIF(rc)
+}
+
+func readZipEntry(f *zip.File) ([]byte, error) {
+	rc, err := f.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+	return io.ReadAll(rc)
+}
+
+func isAudioEntry(name string) bool {
+	for _, suffix := range []string{".wav", ".mp3", ".ogg", ".flac"} {
+		if len(name) >= len(suffix) && name[len(name)-len(suffix):] == suffix {
+			return true
+		}
+	}
+	return false
+}
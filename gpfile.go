@@ -20,6 +20,9 @@ import (
 //	formats := parsegp.SupportedFormats()
 //	fmt.Println(formats) // Output: [".gp3" ".gp4" ".gp5"]
 func SupportedFormats() []string {
+	if exts := RegisteredExtensions(); len(exts) > 0 {
+		return exts
+	}
 	return []string{".gp3", ".gp4", ".gp5"} //, ".gpx"}
 }
 
@@ -73,30 +76,69 @@ func NewGPFile(p string) (gp *GPFile, err error) {
 // header detection, or information extraction process.
 func (gp *GPFile) LoadHeader() error {
 	// return gp.loadFileHeader()
-	if fi, err := os.Stat(gp.FullPath); err != nil || fi.Size() == 0 {
-		if err == nil {
-			return errors.New("file is empty or does not exist")
+	if gp.reader == nil {
+		if fi, err := os.Stat(gp.FullPath); err != nil || fi.Size() == 0 {
+			if err == nil {
+				return errors.New("file is empty or does not exist")
+			}
+			return err
 		}
-		return err
 	}
 
-	f, err := os.Open(gp.FullPath)
+	fo, closer, err := gp.readSeeker()
 	if err != nil {
-		_, err = fmt.Fprintf(os.Stderr, "Error opening file %s: %v\n", gp.FullPath, err)
+		_, _ = fmt.Fprintf(os.Stderr, "Error opening file %s: %v\n", gp.FullPath, err)
 		return err
 	}
-	defer func(f *os.File) {
-		err := f.Close()
-		if err != nil {
+	defer func() {
+		if err := closer.Close(); err != nil {
 			fmt.Println(err)
 		}
-	}(f)
+	}()
 
-	fo, err := gpSeek(f)
+	fo, err = gpSeek(fo)
 	if err != nil {
 		return err
 	}
 
+	// A plain ZIP archive (gp7/gp8's "PK\x03\x04" magic) is handled
+	// directly here rather than through the Format registry below, since
+	// loadGPFile needs random access to the archive and currently reopens
+	// gp.FullPath itself.
+	magic := make([]byte, 4)
+	if _, err := io.ReadFull(fo, magic); err == nil && string(magic) == "PK\x03\x04" {
+		if gp.reader != nil {
+			return fmt.Errorf("LoadHeader: gp7 files are not yet supported from a non-file reader")
+		}
+		return gp.loadGPFile()
+	}
+	if _, err := gpSeek(fo); err != nil {
+		return err
+	}
+
+	// Dispatch through the Format registry first so third-party formats
+	// (gp7, custom dialects, ...) registered via Register() are picked up
+	// without touching this switch. Only legacyFormat falls through to the
+	// existing gp3/gp4/gp5/gpx handling below.
+	if format, derr := Detect(fo); derr == nil {
+		if _, ok := format.(legacyFormat); !ok {
+			decoder, err := format.NewDecoder(fo)
+			if err != nil {
+				return err
+			}
+			decoded, err := decoder.Decode()
+			if err != nil {
+				return err
+			}
+			fullPath := gp.FullPath
+			reader := gp.reader
+			*gp = *decoded
+			gp.FullPath = fullPath
+			gp.reader = reader
+			return nil
+		}
+	}
+
 	headerlen, head, err := headerLen(fo)
 	if err != nil {
 		return err
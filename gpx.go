@@ -1,8 +1,8 @@
 package parsegp
 
 import (
-	"bytes"
-	"encoding/binary"
+	"bufio"
+	"encoding/xml"
 	"fmt"
 	"io"
 	"os"
@@ -10,113 +10,87 @@ import (
 
 /* GPX Dont work - experimental */
 
-// BitStream represents a stream of bits that can be read bit by bit.
+// BitStream reads big-endian bits out of an underlying reader. Unlike a
+// naive one-bit-at-a-time reader it pulls a full byte into bufBits on each
+// refill and serves ReadBits/PeekBits out of that accumulator, so a BCFZ
+// stream's many small (2-4 bit) reads don't each cost a reader call.
 type BitStream struct {
-	reader io.Reader
-	buffer byte
-	count  uint8
+	br      *bufio.Reader
+	bufBits uint64
+	numBits uint
+	offset  int64
 }
 
 // NewBitStream creates a new BitStream reader.
 func newBitStream(reader io.Reader) *BitStream {
-	return &BitStream{
-		reader: reader,
-		buffer: 0,
-		count:  0,
-	}
+	return &BitStream{br: bufio.NewReader(reader)}
 }
 
-// ReadBit reads a single bit from the stream.
-func (bs *BitStream) readBit() (uint8, error) {
-	if bs.count == 0 {
-		if err := binary.Read(bs.reader, binary.BigEndian, &bs.buffer); err != nil {
-			return 0, err
-		}
-		bs.count = 8
-	}
+// Reset discards any buffered bits and starts reading from r.
+func (bs *BitStream) Reset(r io.Reader) {
+	bs.br = bufio.NewReader(r)
+	bs.bufBits = 0
+	bs.numBits = 0
+	bs.offset = 0
+}
 
-	bs.count--
-	return (bs.buffer >> bs.count) & 1, nil
+// Offset returns the number of bits consumed from the underlying reader so
+// far, including any still sitting in the accumulator unread.
+func (bs *BitStream) Offset() int64 {
+	return bs.offset
 }
 
-// ReadBits reads n bits from the stream and returns them as an integer.
-func (bs *BitStream) readBits(n uint8) (uint32, error) {
-	var value uint32
-	for i := uint8(0); i < n; i++ {
-		bit, err := bs.readBit()
+// fill tops up the accumulator until it holds at least n bits (n <= 32).
+func (bs *BitStream) fill(n uint) error {
+	for bs.numBits < n {
+		b, err := bs.br.ReadByte()
 		if err != nil {
-			return 0, err
+			return err
 		}
-		value = (value << 1) | uint32(bit)
+		bs.bufBits = (bs.bufBits << 8) | uint64(b)
+		bs.numBits += 8
+		bs.offset += 8
 	}
-	return value, nil
+	return nil
 }
 
-// DecompressBCFZ decompresses a BCFZ file.
-func decompressBCFZ(file *os.File) ([]byte, error) {
-	// Read the expected decompressed length (32-bit little-endian integer)
-	var expectedLength uint32
-	if err := binary.Read(file, binary.LittleEndian, &expectedLength); err != nil {
-		return nil, err
+// PeekBits returns the next n bits (n <= 32) without consuming them.
+func (bs *BitStream) PeekBits(n uint) (uint32, error) {
+	if n == 0 {
+		return 0, nil
 	}
-	// fmt.Println("expectedLength", expectedLength)
-
-	bitStream := newBitStream(file)
-	var decompressed bytes.Buffer
+	if err := bs.fill(n); err != nil {
+		return 0, err
+	}
+	return uint32(bs.bufBits>>(bs.numBits-n)) & ((1 << n) - 1), nil
+}
 
-	for {
-		// Read the next bit to determine the chunk type
-		chunkType, err := bitStream.readBit()
-		if err == io.EOF {
-			break
-		}
-		if err != nil {
-			return nil, err
-		}
+// ReadBits reads and consumes the next n bits (n <= 32) as an integer.
+func (bs *BitStream) ReadBits(n uint) (uint32, error) {
+	v, err := bs.PeekBits(n)
+	if err != nil {
+		return 0, err
+	}
+	bs.numBits -= n
+	return v, nil
+}
 
-		if chunkType == 0 {
-			// Uncompressed chunk
-			length, err := bitStream.readBits(2) // Read 2 bits for length
-			if err != nil {
-				return nil, err
-			}
-			buf := make([]byte, length)
-			if _, err := io.ReadFull(file, buf); err != nil {
-				return nil, err
-			}
-			decompressed.Write(buf)
-		} else {
-			// Compressed chunk
-			wordSize, err := bitStream.readBits(4) // Read 4 bits for word size
-			if err != nil {
-				return nil, err
-			}
-			offset, err := bitStream.readBits(uint8(wordSize)) // Read wordSize bits for offset
-			if err != nil {
-				return nil, err
-			}
-			length, err := bitStream.readBits(uint8(wordSize)) // Read wordSize bits for length
-			if err != nil {
-				return nil, err
-			}
-			start := decompressed.Len() - int(offset)
-			if start < 0 || start+int(length) > decompressed.Len() {
-				fmt.Println("start:", start, "length:", length)
-				return nil, fmt.Errorf("invalid offset/length in compressed chunk")
-			}
-			buf := decompressed.Bytes()[start : start+int(length)]
-			fmt.Println("buf:", string(buf))
-			decompressed.Write(buf)
-		}
+// Align discards any bits left in the accumulator up to the next byte
+// boundary of the underlying reader.
+func (bs *BitStream) Align() {
+	bs.numBits -= bs.numBits % 8
+}
 
-		// Stop if we reach the expected decompressed length or the file ends
-		if uint32(decompressed.Len()) >= expectedLength {
-			break
-		}
-	}
+// readBit reads a single bit from the stream; kept for callers that still
+// think in terms of individual bits.
+func (bs *BitStream) readBit() (uint8, error) {
+	v, err := bs.ReadBits(1)
+	return uint8(v), err
+}
 
-	// Return the decompressed data
-	return decompressed.Bytes(), nil
+// readBits reads n bits from the stream and returns them as an integer.
+func (bs *BitStream) readBits(n uint8) (uint32, error) {
+	return bs.ReadBits(uint(n))
 }
 
 // loadGPXFile loads a GPX file.
@@ -139,35 +113,68 @@ func (gp *GPFile) loadGPXFile() error {
 		}
 	}(f)
 
-	data, err := decompressBCFZ(f)
+	// NewBCFZReader resolves back-references against a bounded 32KB sliding
+	// window instead of decompressBCFZ's old approach of growing one
+	// buffer for the whole file and indexing back into it, so a large score
+	// no longer costs a second multi-megabyte allocation on top of the one
+	// io.ReadAll makes below to hand parseBCFS its []byte.
+	z, err := NewBCFZReader(f)
 	if err != nil {
 		return err
 	}
-	fmt.Println("Decompressed data:", data)
-
-	/*
-
-		data := make([]byte, fi.Size())
-		zeros := 0
-		for {
-			data = data[:cap(data)]
-			fmt.Println(string(data))
-			n, err := f.Read(data)
-			if err != nil {
-				if err == io.EOF {
-					fmt.Println("EOF")
-					break
-				}
-				fmt.Println("err:", err)
-				return err
-			}
-			data = data[:n]
-			for _, b := range data {
-				if b == 0 {
-					zeros++
-				}
-			}
-		}
-	*/
+	defer z.Close()
+
+	data, err := io.ReadAll(z)
+	if err != nil {
+		return err
+	}
+
+	bcfs, err := parseBCFS(data)
+	if err != nil {
+		return err
+	}
+
+	gpif, ok := bcfs.Files["Content/score.gpif"]
+	if !ok {
+		return fmt.Errorf("gpx: archive has no Content/score.gpif entry")
+	}
+
+	return gp.loadGPIF(gpif)
+}
+
+// gpifDocument is a partial mapping of score.gpif, just enough to populate
+// a GPFile's metadata and track names from a .gpx file.
+type gpifDocument struct {
+	XMLName xml.Name `xml:"GPIF"`
+	Score   struct {
+		Title  string `xml:"Title"`
+		Artist string `xml:"Artist"`
+		Album  string `xml:"Album"`
+	} `xml:"Score"`
+	Tracks struct {
+		Track []struct {
+			Name string `xml:"Name"`
+		} `xml:"Track"`
+	} `xml:"Tracks"`
+}
+
+// loadGPIF parses a score.gpif entry (read out of the BCFS virtual
+// filesystem) and populates gp from it.
+func (gp *GPFile) loadGPIF(r io.Reader) error {
+	var doc gpifDocument
+	if err := xml.NewDecoder(r).Decode(&doc); err != nil {
+		return fmt.Errorf("gpx: decoding score.gpif: %w", err)
+	}
+
+	gp.Title = doc.Score.Title
+	gp.Artist = doc.Score.Artist
+	gp.Album = doc.Score.Album
+
+	gp.Tracks = gp.Tracks[:0]
+	for _, t := range doc.Tracks.Track {
+		gp.Tracks = append(gp.Tracks, Track{Name: t.Name})
+	}
+	gp.TrackCount = len(gp.Tracks)
+
 	return nil
 }
@@ -0,0 +1,120 @@
+// Description: This file adds ParseDir, a bulk entry point for parsing
+// every Guitar Pro file in a directory, modeled after go/parser's ParseDir.
+
+package parsegp
+
+import (
+	"fmt"
+	"io/fs"
+	"path/filepath"
+	"strings"
+)
+
+// Mode is a bitmask of optional ParseDir/NewParserWithMode behaviors,
+// following the go/parser parser.Mode pattern (ParseComments, Trace,
+// SkipObjectResolution, ...). Recursive is ParseDir's own bit; the rest
+// (song.go) gate what a single Parser does while reading.
+type Mode uint
+
+const (
+	// Recursive makes ParseDir walk subdirectories via filepath.WalkDir
+	// instead of only scanning path's immediate entries.
+	Recursive Mode = 1 << iota
+	// SkipNotes makes Parse stop right after readSongStructure - header,
+	// channels, measure headers, tracks - without reading any measure's
+	// beats. Parse with SkipNotes is the cheap path for indexing a tab
+	// library by title/artist/track list alone. ParseDir does not pass
+	// this bit on to the parsers it opens.
+	SkipNotes
+	// SkipLyrics still reads past the lyrics track's bytes, since the
+	// format offers no way to skip them without losing the read position,
+	// but discards the result instead of storing it on Lyric.
+	SkipLyrics
+	// ParseChordDiagrams is accepted for interface parity with the modes
+	// above but is currently a no-op: a beat's chord diagram is only ever
+	// present when the beat's own flag byte says so, so there is no way to
+	// skip decoding one without desynchronizing the rest of the byte
+	// stream behind it.
+	ParseChordDiagrams
+	// Trace makes Parse log a one-line message at each major section
+	// boundary (channels, measure headers, tracks, each track's measures)
+	// through Logger, if one is set. It has no effect without a Logger.
+	Trace
+	// AllErrors makes NewParserWithMode leave Parser.Strict false, so Parse
+	// accumulates a ParseWarning per unreadable field and carries on
+	// instead of aborting at the first one. Unset, NewParserWithMode sets
+	// Strict true, matching go/parser's default of stopping at the first
+	// error unless AllErrors is requested.
+	AllErrors
+)
+
+// ParseDir parses every Guitar Pro file in path into a map keyed by the
+// file's path relative to path, modeled after go/parser's ParseDir. An
+// entry is parsed if filter reports true for it, or, when filter is nil, if
+// its extension is .gp3, .gp4 or .gp5. Subdirectories are walked only when
+// mode has Recursive set; keying by the relative path (rather than the bare
+// filename) keeps files that share a name in different subdirectories from
+// colliding in the result. On partial failure, ParseDir returns the parsers
+// that succeeded so far alongside the first error encountered, matching the
+// documented incomplete-map-plus-error semantics of the stdlib equivalent.
+func ParseDir(path string, filter func(fs.DirEntry) bool, mode Mode) (map[string]*Parser, error) {
+	parsers := make(map[string]*Parser)
+	var firstErr error
+
+	walk := func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if firstErr == nil {
+				firstErr = err
+			}
+			return nil
+		}
+
+		if d.IsDir() {
+			if p != path && mode&Recursive == 0 {
+				return fs.SkipDir
+			}
+			return nil
+		}
+
+		if filter != nil {
+			if !filter(d) {
+				return nil
+			}
+		} else if !hasGPExtension(d.Name()) {
+			return nil
+		}
+
+		parser, err := NewParserFromFile(p)
+		if err != nil {
+			if firstErr == nil {
+				firstErr = fmt.Errorf("%s: %w", p, err)
+			}
+			return nil
+		}
+
+		rel, err := filepath.Rel(path, p)
+		if err != nil {
+			rel = p
+		}
+		parsers[rel] = parser
+
+		return nil
+	}
+
+	if err := filepath.WalkDir(path, walk); err != nil {
+		return parsers, err
+	}
+
+	return parsers, firstErr
+}
+
+// hasGPExtension reports whether name ends in .gp3, .gp4 or .gp5 - the
+// default filter ParseDir applies when the caller does not supply one.
+func hasGPExtension(name string) bool {
+	switch strings.ToLower(filepath.Ext(name)) {
+	case ".gp3", ".gp4", ".gp5":
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,88 @@
+package parsegp
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// minimalGP3 builds the smallest byte stream NewParser will accept as a
+// v3.00 file: the 31-byte version field, then eight empty
+// readStringByteSizeOfInteger fields (Title, Subtitle, Artist, Album,
+// LyricsAuthor, MusicAuthor, Copyright, Tab) - gp3 has no
+// Instructions/Comments block, so nothing else is required for NewParser
+// to return successfully.
+func minimalGP3(t *testing.T) []byte {
+	t.Helper()
+	const version = "FICHIER GUITAR PRO v3.00"
+
+	buf := make([]byte, 0, 31+8*2)
+	buf = append(buf, byte(len(version)))
+	field := make([]byte, 30)
+	copy(field, version)
+	buf = append(buf, field...)
+
+	for i := 0; i < 8; i++ {
+		buf = append(buf, 1, 0) // num=1 (size=0), inner length=0: an empty string
+	}
+	return buf
+}
+
+func writeGP3(t *testing.T, path string) {
+	t.Helper()
+	if err := os.WriteFile(path, minimalGP3(t), 0644); err != nil {
+		t.Fatalf("WriteFile(%s): %v", path, err)
+	}
+}
+
+func TestParseDirKeysByRelativePath(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.Mkdir(filepath.Join(dir, "sub"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	writeGP3(t, filepath.Join(dir, "song.gp3"))
+	writeGP3(t, filepath.Join(dir, "sub", "song.gp3"))
+
+	parsers, err := ParseDir(dir, nil, Recursive)
+	if err != nil {
+		t.Fatalf("ParseDir: %v", err)
+	}
+
+	wantKeys := map[string]bool{
+		"song.gp3":                       true,
+		filepath.Join("sub", "song.gp3"): true,
+	}
+	if len(parsers) != len(wantKeys) {
+		t.Fatalf("ParseDir returned %d entries, want %d: %v", len(parsers), len(wantKeys), keysOf(parsers))
+	}
+	for key := range wantKeys {
+		if _, ok := parsers[key]; !ok {
+			t.Errorf("ParseDir result missing key %q, got keys %v", key, keysOf(parsers))
+		}
+	}
+}
+
+func TestParseDirNonRecursiveSkipsSubdirs(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.Mkdir(filepath.Join(dir, "sub"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	writeGP3(t, filepath.Join(dir, "song.gp3"))
+	writeGP3(t, filepath.Join(dir, "sub", "song.gp3"))
+
+	parsers, err := ParseDir(dir, nil, 0)
+	if err != nil {
+		t.Fatalf("ParseDir: %v", err)
+	}
+	if _, ok := parsers["song.gp3"]; !ok || len(parsers) != 1 {
+		t.Fatalf("ParseDir (non-recursive) = %v, want only {song.gp3}", keysOf(parsers))
+	}
+}
+
+func keysOf(m map[string]*Parser) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	return keys
+}
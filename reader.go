@@ -0,0 +1,79 @@
+package parsegp
+
+import (
+	"bytes"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+func extOf(name string) string {
+	return filepath.Ext(name)
+}
+
+func openFile(path string) (*os.File, error) {
+	return os.Open(path)
+}
+
+// NewGPFileFromReader creates a GPFile from an arbitrary io.Reader instead
+// of a path on disk, so files pulled from HTTP, an embed.FS, or any other
+// non-filesystem source can be parsed. name is only used for its extension,
+// to validate the format the same way NewGPFile does; it does not have to
+// exist on disk.
+func NewGPFileFromReader(name string, r io.Reader) (*GPFile, error) {
+	ext := extOf(name)
+	if !isSupportedExt(ext) {
+		return nil, &notGPFile{msg: "no supported file format"}
+	}
+
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	gp := &GPFile{FullPath: name}
+	gp.reader = bytes.NewReader(data)
+	return gp, nil
+}
+
+// NewGPFileFromFS creates a GPFile by opening name from fsys, which lets
+// callers parse files out of an embed.FS or any other io/fs.FS without
+// reaching for os.Open directly.
+func NewGPFileFromFS(fsys fs.FS, name string) (*GPFile, error) {
+	f, err := fsys.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	return NewGPFileFromReader(name, f)
+}
+
+// readSeeker returns an io.ReadSeeker for gp's content: the in-memory
+// reader set by NewGPFileFromReader/NewGPFileFromFS if present, or the
+// file at gp.FullPath opened fresh otherwise. The returned closer is a
+// no-op for the in-memory case.
+func (gp *GPFile) readSeeker() (io.ReadSeeker, io.Closer, error) {
+	if gp.reader != nil {
+		if _, err := gp.reader.Seek(0, io.SeekStart); err != nil {
+			return nil, nil, err
+		}
+		return gp.reader, io.NopCloser(nil), nil
+	}
+
+	f, err := openFile(gp.FullPath)
+	if err != nil {
+		return nil, nil, err
+	}
+	return f, f, nil
+}
+
+func isSupportedExt(ext string) bool {
+	for _, format := range SupportedFormats() {
+		if ext == format {
+			return true
+		}
+	}
+	return ext == ".gp3" || ext == ".gp4" || ext == ".gp5" || ext == ".gpx"
+}
@@ -0,0 +1,677 @@
+// Description: This file extends Parser (gp5parser.go) with per-version
+// dispatch so it can read gp3, gp4 and gp5 files through the same code
+// path, and with Parse, the entry point that assembles a fully decoded,
+// version-tagged TabFile from them.
+
+package parsegp
+
+import (
+	"fmt"
+	"io"
+)
+
+// versionProfile captures the layout differences between gp3, gp4 and gp5
+// that Parser has to account for once it moves past the metadata block
+// NewParser already reads. Modeled after the version branching pyguitarpro
+// does in its GPXParser base class, keyed the same way VersionIndex
+// already is.
+type versionProfile struct {
+	// gp4Plus is true for every version newer than gp3: it gates the
+	// instructions/comments block, the wider page setup, the mix change's
+	// tempo-rename fields and the chord diagram's newFormat flag.
+	gp4Plus bool
+	// voiceCount is how many voices each beat carries. gp3 and gp4 only
+	// ever had one; gp5 introduced a second.
+	voiceCount int
+	// hasDirections is true for gp5, which appends an RSE master effect
+	// block to the page setup and an RSE instrument block to each track.
+	hasDirections bool
+	// hasChordDiagramV2 is true for gp4 and gp5, whose chord diagrams are
+	// prefixed by a flag choosing between the old fixed layout and a
+	// richer one with a base fret and barres.
+	hasChordDiagramV2 bool
+	// hasFingering is true for gp5, whose note effects carry an extra
+	// left-hand/right-hand fingering pair that gp3/gp4 do not.
+	hasFingering bool
+}
+
+// versionProfiles is indexed by VersionIndex and mirrors VERSIONS.
+var versionProfiles = []versionProfile{
+	{gp4Plus: false, voiceCount: 1, hasDirections: false, hasChordDiagramV2: false, hasFingering: false}, // v3.00
+	{gp4Plus: true, voiceCount: 1, hasDirections: false, hasChordDiagramV2: true, hasFingering: false},   // v4.00
+	{gp4Plus: true, voiceCount: 1, hasDirections: false, hasChordDiagramV2: true, hasFingering: false},   // v4.06
+	{gp4Plus: true, voiceCount: 2, hasDirections: true, hasChordDiagramV2: true, hasFingering: true},     // v5.00
+	{gp4Plus: true, voiceCount: 2, hasDirections: true, hasChordDiagramV2: true, hasFingering: true},     // v5.10
+}
+
+// profileFor returns the versionProfile for versionIndex, falling back to
+// the most capable (gp5) profile for an index outside VERSIONS' range.
+// Parser and Writer both key off this so reading and writing a given
+// VersionIndex always agree on layout.
+func profileFor(versionIndex int) versionProfile {
+	if versionIndex < 0 || versionIndex >= len(versionProfiles) {
+		return versionProfiles[len(versionProfiles)-1]
+	}
+	return versionProfiles[versionIndex]
+}
+
+// profile returns the versionProfile for the file this Parser is reading.
+// It falls back to the most capable (gp5) profile if called before
+// IsSupportedVersion set VersionIndex, which NewParser always does before
+// any other Parser method runs.
+func (p *Parser) profile() versionProfile {
+	return profileFor(p.VersionIndex)
+}
+
+// readSongStructure reads everything NewParser's header scan left behind
+// up to, but not including, the per-track measure bodies: lyrics, tempo,
+// key signature, page setup, channels, measure headers and tracks. It
+// fires the corresponding Visitor callbacks as each piece lands, if a
+// Visitor is set. Parse and Walk share it, since the streaming Walk entry
+// point differs from Parse only in what it does with the measure bodies
+// that follow, not in how it gets there.
+func (p *Parser) readSongStructure() (*Tempo, error) {
+	profile := p.profile()
+
+	if profile.voiceCount > 1 {
+		// gp5 carries its lyrics track ahead of the tempo/key signature;
+		// gp3 and gp4 have none. The bytes are always read, even under
+		// SkipLyrics, since there is no way to skip past them without
+		// losing the read position; SkipLyrics only discards the result.
+		lyric := p.readLyrics()
+		if p.Mode&SkipLyrics == 0 {
+			p.Lyric = lyric
+		}
+	}
+
+	tempoValue, err := p.readInt()
+	if err != nil {
+		return nil, err
+	}
+	p.TempoValue = int(tempoValue)
+
+	if profile.gp4Plus {
+		// gp4+ adds a "humanize tempo" percentage right after the tempo
+		// value; gp3 does not.
+		if _, err := p.readByte(); err != nil {
+			return nil, err
+		}
+	}
+
+	keySignature, err := p.readKeySignature()
+	if err != nil {
+		return nil, err
+	}
+	p.GlobalKeySignature = int(keySignature)
+	p.skip(1)
+
+	p.readPageSetup()
+	if profile.hasDirections {
+		if err := p.readDirections(); err != nil {
+			return nil, err
+		}
+		p.readRSEMasterEffect()
+	}
+
+	if err := p.fireHeader(p.songHeader()); err != nil {
+		return nil, err
+	}
+
+	p.trace("channels")
+	channels, err := p.readChannels()
+	if err != nil {
+		return nil, err
+	}
+	p.Channels = channels
+	p.indexPercussionChannels()
+	for _, channel := range p.Channels {
+		if err := p.fireChannel(channel); err != nil {
+			return nil, err
+		}
+	}
+
+	measureCount, err := p.readInt()
+	if err != nil {
+		return nil, err
+	}
+	trackCount, err := p.readInt()
+	if err != nil {
+		return nil, err
+	}
+
+	p.trace("measureHeaders")
+	headers, err := p.readMeasureHeaders(int(measureCount))
+	if err != nil {
+		return nil, err
+	}
+	p.MeasureHeaders = headers
+	for _, header := range p.MeasureHeaders {
+		if err := p.fireMeasureHeader(header); err != nil {
+			return nil, err
+		}
+	}
+
+	p.trace("tracks")
+	tracks, err := p.readTracks(int(trackCount))
+	if err != nil {
+		return nil, err
+	}
+	p.Tracks = tracks
+	for _, track := range p.Tracks {
+		if err := p.fireTrack(track); err != nil {
+			return nil, err
+		}
+	}
+
+	return &Tempo{Value: tempoValue}, nil
+}
+
+// Parse reads everything NewParser's header scan left behind - lyrics,
+// tempo, key signature, page setup, channels, measure headers, tracks and
+// every measure's beats - and returns the result as a TabFile tagged with
+// the Major/Minor version it came from. Callers only need NewParser and
+// Parse; they never have to branch on the file's dialect themselves, since
+// every gp3/gp4/gp5 difference is resolved internally via profile().
+//
+// In Strict mode, Parse aborts with an error as soon as any field fails to
+// read. Otherwise it carries on, records a ParseWarning for each one, and
+// copies p.Warnings onto the returned TabFile.
+//
+// If Mode has SkipNotes set, Parse stops right here and returns a TabFile
+// whose Tracks carry headers, channels and tuning but no Measures - the
+// cheap path for indexing a tab library by title/artist/track list alone.
+func (p *Parser) Parse() (*TabFile, error) {
+	tempo, err := p.readSongStructure()
+	if err != nil {
+		return nil, err
+	}
+
+	if p.Mode&SkipNotes == 0 {
+		for t := range p.Tracks {
+			p.tiedNoteCache = [7]struct {
+				Value uint8
+				Set   bool
+			}{}
+			p.curTrack = t + 1
+			p.trace(fmt.Sprintf("track[%d].measures", t))
+			for _, header := range p.MeasureHeaders {
+				p.curMeasure = header.Number
+				measure := Measure{Header: header, Start: header.Start}
+				if err := p.readMeasure(&measure, &p.Tracks[t], tempo, int8(p.GlobalKeySignature)); err != nil {
+					return nil, err
+				}
+				p.Tracks[t].Measures = append(p.Tracks[t].Measures, measure)
+			}
+		}
+		p.curTrack, p.curMeasure, p.curBeat = 0, 0, 0
+	}
+
+	p.TabFile = &TabFile{
+		Major:              p.Major,
+		Minor:              p.Minor,
+		Title:              p.Title,
+		Subtitle:           p.Subtitle,
+		Artist:             p.Artist,
+		Album:              p.Album,
+		LyricsAuthor:       p.LyricsAuthor,
+		MusicAuthor:        p.MusicAuthor,
+		Copyright:          p.Copyright,
+		Tab:                p.Tab,
+		Instructions:       p.Instructions,
+		Lyric:              p.Lyric,
+		TempoValue:         p.TempoValue,
+		GlobalKeySignature: p.GlobalKeySignature,
+		Channels:           p.Channels,
+		TrackCount:         len(p.Tracks),
+		MeasureHeaders:     p.MeasureHeaders,
+		Tracks:             p.Tracks,
+		Warnings:           p.Warnings,
+		ParseErrors:        p.ParseErrors,
+		TempoName:          p.TempoName,
+		HideTempo:          p.HideTempo,
+		Key:                p.Key,
+		Octave:             p.Octave,
+		Directions:         p.Directions,
+		MasterReverb:       p.MasterReverb,
+	}
+	for _, comment := range p.Comments {
+		if p.TabFile.Comments != "" {
+			p.TabFile.Comments += "\n"
+		}
+		p.TabFile.Comments += comment
+	}
+
+	return p.TabFile, nil
+}
+
+// Walk is the streaming counterpart to Parse: it drives the same decode
+// but never retains a track's measures, so a Visitor can index a .gp4
+// file - or thousands of them, one Parser each - without Parse's TabFile
+// holding every beat and note in memory at once. v's callbacks fire from
+// the same call sites Parse's do (readSongStructure for the header,
+// channels, measure headers and tracks; readBeat/readNote/readMixChange
+// for everything within a measure), so Walk(NewCollector()) sees what
+// Parse builds, just assembled by v instead of by Parse itself. One
+// difference remains: beats with no notes in any voice, which readMeasure
+// drops from Parse's measures, reach v and stay in a Collector's.
+func (p *Parser) Walk(v Visitor) error {
+	p.Visitor = v
+
+	tempo, err := p.readSongStructure()
+	if err != nil {
+		return err
+	}
+
+	for t := range p.Tracks {
+		p.tiedNoteCache = [7]struct {
+			Value uint8
+			Set   bool
+		}{}
+		for _, header := range p.MeasureHeaders {
+			measure := Measure{Header: header, Start: header.Start}
+			if err := p.readMeasure(&measure, &p.Tracks[t], tempo, int8(p.GlobalKeySignature)); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// songHeader snapshots the metadata fields readSongStructure has filled
+// in by the time it is ready to fire OnHeader: the song-level metadata
+// Parse copies onto TabFile except Lyric and Comments, which no Visitor
+// callback reports, and Channels, MeasureHeaders and Tracks, which get
+// their own Visitor callbacks once read.
+func (p *Parser) songHeader() SongHeader {
+	return SongHeader{
+		Major:              p.Major,
+		Minor:              p.Minor,
+		Title:              p.Title,
+		Subtitle:           p.Subtitle,
+		Artist:             p.Artist,
+		Album:              p.Album,
+		LyricsAuthor:       p.LyricsAuthor,
+		MusicAuthor:        p.MusicAuthor,
+		Copyright:          p.Copyright,
+		Tab:                p.Tab,
+		Instructions:       p.Instructions,
+		TempoValue:         p.TempoValue,
+		GlobalKeySignature: p.GlobalKeySignature,
+		TempoName:          p.TempoName,
+		HideTempo:          p.HideTempo,
+		Key:                p.Key,
+		Octave:             p.Octave,
+		Directions:         p.Directions,
+		MasterReverb:       p.MasterReverb,
+	}
+}
+
+// Parse reads a Guitar Pro file from r - gp3, gp4 or gp5, whichever
+// IsSupportedVersion recognizes from its header - and returns the fully
+// decoded TabFile in one call. It is a thin convenience wrapper over
+// NewParserFromReader followed by (*Parser).Parse for callers who have no
+// further use for the Parser itself; reach for NewParserFromReader directly
+// when the caller also wants Warnings, Strict mode, or WriteSong/WriteGP5
+// round-tripping off the same Parser.
+func Parse(r io.Reader) (*TabFile, error) {
+	p, err := NewParserFromReader(r)
+	if err != nil {
+		return nil, err
+	}
+	return p.Parse()
+}
+
+// directionNames lists the 19 named direction markers (coda, segno, fine,
+// ...) gp5 stores as measure indices, in the fixed order they appear in the
+// file.
+var directionNames = []string{
+	"Coda", "DoubleCoda", "Segno", "SegnoSegno", "Fine",
+	"DaCapo", "DaCapoAlCoda", "DaCapoAlDoubleCoda", "DaCapoAlFine",
+	"DaSegno", "DaSegnoAlCoda", "DaSegnoAlDoubleCoda", "DaSegnoAlFine",
+	"DaSegnoSegno", "DaSegnoSegnoAlCoda", "DaSegnoSegnoAlDoubleCoda",
+	"DaSegnoSegnoAlFine", "DaCoda", "DaDoubleCoda",
+}
+
+// readDirections reads gp5's tempo name, key/octave and the 19 named
+// direction markers that follow the page setup and precede the RSE master
+// effect. gp3 and gp4 have none of this, so Parse only calls it when
+// profile.hasDirections is set.
+func (p *Parser) readDirections() error {
+	tempoName, err := p.readStringByteSizeOfInteger()
+	if err != nil {
+		return p.fail("tempoName", err)
+	}
+	p.TempoName = tempoName
+
+	hideTempo, err := p.readByte()
+	if err != nil {
+		return p.fail("hideTempo", err)
+	}
+	p.HideTempo = hideTempo != 0
+
+	key, err := p.readByte()
+	if err != nil {
+		return p.fail("key", err)
+	}
+	p.Key = int(key)
+
+	octave, err := p.readByte()
+	if err != nil {
+		return p.fail("octave", err)
+	}
+	p.Octave = int8(octave)
+
+	directions := make(map[string]int16, len(directionNames))
+	for _, name := range directionNames {
+		value, err := p.readShort()
+		if err != nil {
+			return p.fail("direction."+name, err)
+		}
+		directions[name] = value
+	}
+	p.Directions = directions
+
+	return nil
+}
+
+// readRSEMasterEffect reads gp5's RSE master effect block that follows the
+// page setup: a master volume, a reserved field, and an equalizer (10 bands
+// plus overall gain) whose individual bands we do not model yet, so they
+// are skipped wholesale the same way readPageSetup already does for fields
+// it does not expose.
+func (p *Parser) readRSEMasterEffect() {
+	masterVolume, err := p.readInt()
+	if err != nil {
+		p.fail("rseMasterEffect.masterVolume", err)
+		return
+	}
+	p.MasterReverb = masterVolume
+	p.skip(4)  // reserved
+	p.skip(11) // equalizer: 10 bands plus overall gain
+}
+
+// readMeasureHeaders reads the count measure headers that describe time
+// signature and repeat structure for the whole song.
+func (p *Parser) readMeasureHeaders(count int) ([]MeasureHeader, error) {
+	headers := make([]MeasureHeader, 0, count)
+	var previousNumerator, previousDenominator int32 = 4, 4
+	start := int32(QUARTER_TIME)
+
+	for i := 0; i < count; i++ {
+		if i > 0 {
+			// The separator byte between measure headers is skipped except
+			// for the very first one.
+			if _, err := p.readByte(); err != nil {
+				return nil, err
+			}
+		}
+
+		flags, err := p.readByte()
+		if err != nil {
+			return nil, err
+		}
+
+		header := MeasureHeader{Number: i + 1, Start: int(start)}
+		if flags&0x01 != 0 {
+			header.RepeatOpen = true
+		}
+
+		numerator := previousNumerator
+		if flags&0x01 != 0 {
+			n, err := p.readByte()
+			if err != nil {
+				return nil, err
+			}
+			numerator = int32(n)
+		}
+		denominator := previousDenominator
+		if flags&0x02 != 0 {
+			d, err := p.readByte()
+			if err != nil {
+				return nil, err
+			}
+			denominator = int32(d)
+		}
+		header.TimeSignature.Numerator = int(numerator)
+		header.TimeSignature.Denominator = Denominator{Value: float64(denominator)}
+		header.TimeSignature.Division = Division{Times: 1, Enters: 1}
+		previousNumerator, previousDenominator = numerator, denominator
+
+		if flags&0x04 != 0 {
+			p.skip(1)
+		}
+		if flags&0x08 != 0 {
+			p.skip(2)
+		}
+		if flags&0x10 != 0 {
+			p.skip(1)
+		}
+		if flags&0x20 != 0 {
+			p.skip(4)
+		}
+		if flags&0x40 != 0 {
+			p.skip(1)
+		}
+		if p.profile().hasDirections && (flags&0x10 != 0 || flags&0x20 != 0) {
+			// gp5 adds one more marker byte when either the key signature
+			// or the time signature changed at this measure.
+			p.skip(1)
+		}
+
+		start += p.getLength(&header)
+		headers = append(headers, header)
+	}
+
+	return headers, nil
+}
+
+// readTracks reads the count track descriptors that follow the measure
+// headers: name, tuning, MIDI channel and, for gp5, the RSE instrument
+// block that rides along with it.
+func (p *Parser) readTracks(count int) ([]Track, error) {
+	tracks := make([]Track, 0, count)
+	for i := 0; i < count; i++ {
+		if _, err := p.readByte(); err != nil { // track flags
+			return nil, err
+		}
+
+		name, err := p.readStringByte(40)
+		if err != nil {
+			return nil, err
+		}
+		track := Track{Number: i + 1, Name: name}
+
+		stringCount, err := p.readInt()
+		if err != nil {
+			return nil, err
+		}
+		for s := 0; s < 7; s++ {
+			tuning, err := p.readInt()
+			if err != nil {
+				return nil, err
+			}
+			if int32(s) < stringCount {
+				track.GuitarStrings = append(track.GuitarStrings, GuitarString{Number: int32(s + 1), Value: tuning})
+			}
+		}
+
+		p.skip(4) // port
+		if err := p.readChannel(&track); err != nil {
+			return nil, err
+		}
+		p.skip(4) // fret count
+		if _, err := p.readInt(); err != nil {
+			return nil, fmt.Errorf("reading track %d capo: %w", i, err)
+		}
+		if _, err := p.readColor(); err != nil {
+			return nil, err
+		}
+
+		if p.profile().hasDirections {
+			// gp5 appends an RSE instrument block (humanize plus an
+			// equalizer) to each track; not modelled yet, so skipped
+			// wholesale like readRSEMasterEffect already does for the
+			// song-wide one.
+			p.skip(13)
+		}
+
+		tracks = append(tracks, track)
+	}
+
+	return tracks, nil
+}
+
+// readChord reads chord information from the beat. gp3's diagram is always
+// the old fixed layout; gp4 and gp5 prefix it with a newFormat
+// flag that chooses between that same old layout and the richer one
+// readNewFormatChord decodes.
+func (p *Parser) readChord(strings []GuitarString, beat *Beat) error {
+	if !p.profile().hasChordDiagramV2 {
+		return p.readOldFormatChord(strings, beat)
+	}
+
+	flags, err := p.readByte()
+	if err != nil {
+		return p.fail("chord.formatFlag", err)
+	}
+	if flags&0x01 == 0 {
+		return p.readOldFormatChord(strings, beat)
+	}
+	return p.readNewFormatChord(strings, beat)
+}
+
+// readNewFormatChord reads gp4/gp5's richer chord diagram: the
+// chord-recognition block (sharp flag, root/kind/extension/bass/
+// alternation), a name, a base fret that offsets every non-muted string,
+// a fret value per string, up to two barre spans, and a per-string
+// fingering assignment.
+func (p *Parser) readNewFormatChord(strings []GuitarString, beat *Beat) error {
+	chord := Chord{Strings: &strings}
+
+	if _, err := p.readByte(); err != nil { // sharp flag; this package does not yet spell roots/bass as sharp vs flat
+		return p.fail("newFormatChord.sharp", err)
+	}
+	p.skip(3)
+
+	root, err := p.readByte()
+	if err != nil {
+		return p.fail("newFormatChord.root", err)
+	}
+	chord.Root = int8(root)
+
+	kind, err := p.readByte()
+	if err != nil {
+		return p.fail("newFormatChord.kind", err)
+	}
+	chord.Kind = int8(kind)
+
+	extension, err := p.readByte()
+	if err != nil {
+		return p.fail("newFormatChord.extension", err)
+	}
+	chord.Extension = int8(extension)
+
+	bass, err := p.readByte()
+	if err != nil {
+		return p.fail("newFormatChord.bass", err)
+	}
+	chord.Bass = int8(bass)
+
+	alternation, err := p.readByte()
+	if err != nil {
+		return p.fail("newFormatChord.alternation", err)
+	}
+	chord.Alternation = int8(alternation)
+
+	p.skip(7) // "add" flag plus reserved bytes, not modelled
+
+	chordName, err := p.readStringByte(21)
+	if err != nil {
+		return p.fail("newFormatChord.name", err)
+	}
+	chord.Name = chordName
+
+	p.skip(4) // fifth/ninth/eleventh alterations; not modelled individually
+
+	baseFret, err := p.readInt()
+	if err != nil {
+		return p.fail("newFormatChord.baseFret", err)
+	}
+	chord.BaseFret = baseFret
+
+	chord.Frets = make([]int32, 7)
+	for i := 0; i < 7; i++ {
+		fret, err := p.readInt()
+		if err != nil {
+			return p.fail(fmt.Sprintf("newFormatChord.fret[%d]", i), err)
+		}
+		if i < len(strings) {
+			chord.Frets[i] = fret
+		}
+	}
+
+	if baseFret > 0 {
+		for i := range chord.Frets {
+			if chord.Frets[i] > 0 {
+				chord.Frets[i] += baseFret - 1
+			}
+		}
+	}
+
+	// Up to two barre spans: a count byte, then each span's fret, start
+	// string and end string in their own parallel byte arrays - the same
+	// shape gp4/gp5 uses, just capped at the two barres a chord frame
+	// realistically ever needs.
+	barreCount, err := p.readByte()
+	if err != nil {
+		return p.fail("newFormatChord.barreCount", err)
+	}
+	var barreFrets, barreStarts, barreEnds [2]byte
+	for i := range barreFrets {
+		if barreFrets[i], err = p.readByte(); err != nil {
+			return p.fail(fmt.Sprintf("newFormatChord.barreFret[%d]", i), err)
+		}
+	}
+	for i := range barreStarts {
+		if barreStarts[i], err = p.readByte(); err != nil {
+			return p.fail(fmt.Sprintf("newFormatChord.barreStart[%d]", i), err)
+		}
+	}
+	for i := range barreEnds {
+		if barreEnds[i], err = p.readByte(); err != nil {
+			return p.fail(fmt.Sprintf("newFormatChord.barreEnd[%d]", i), err)
+		}
+	}
+	for i := 0; i < int(barreCount) && i < len(barreFrets); i++ {
+		chord.Barres = append(chord.Barres, BarreSpan{
+			Fret:        int32(barreFrets[i]),
+			StartString: int32(barreStarts[i]),
+			EndString:   int32(barreEnds[i]),
+		})
+	}
+
+	p.skip(7) // per-string omission flags; not modelled
+	p.skip(1) // reserved
+
+	for i := range chord.Fingerings {
+		finger, err := p.readByte()
+		if err != nil {
+			return p.fail(fmt.Sprintf("newFormatChord.fingering[%d]", i), err)
+		}
+		chord.Fingerings[i] = int8(finger)
+	}
+
+	showFingering, err := p.readByte()
+	if err != nil {
+		return p.fail("newFormatChord.showFingering", err)
+	}
+	chord.ShowFingering = showFingering != 0
+	chord.ShowDiagram = true
+
+	if len(strings) > 0 {
+		beat.Chord = chord
+	}
+
+	return nil
+}
@@ -0,0 +1,871 @@
+// Description: This file implements Writer, the inverse of Parser: it
+// serializes a TabFile back into gp3/gp4 bytes via WriteSong - gp5writer.go
+// adds the gp5 (v5.10) counterpart, WriteGP5Song, reusing the same
+// low-level write* methods and the profileFor-driven branches (voiceCount,
+// hasFingering, ...) most of them already take. Fields the parser does not
+// retain on TabFile at all (track color, capo, the RSE blocks' individual
+// settings) are written as their zero value, the same way the parser
+// leaves them unset today.
+//
+// This is a different writer than the GPFile.Encode in writer.go: that one
+// mirrors the separate GPFile/LoadBody path's byte layout, while this one
+// mirrors Parser/Parse's.
+
+package parsegp
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"math"
+	"strings"
+)
+
+// Writer serializes a TabFile into the byte layout Parser reads. Its
+// low-level write* methods are named to mirror the matching Parser read*
+// method, so the two stay easy to read side by side.
+type Writer struct {
+	buf          *bytes.Buffer
+	VersionIndex int
+}
+
+// NewWriter creates a Writer ready for WriteSong. VersionIndex is set by
+// WriteSong itself, from the TabFile's Major/Minor fields.
+func NewWriter() *Writer {
+	return &Writer{buf: &bytes.Buffer{}}
+}
+
+// profile returns the versionProfile WriteSong is laying the file out with.
+func (w *Writer) profile() versionProfile {
+	return profileFor(w.VersionIndex)
+}
+
+func (w *Writer) writeInt(v int32) {
+	var b [4]byte
+	b[0] = byte(v)
+	b[1] = byte(v >> 8)
+	b[2] = byte(v >> 16)
+	b[3] = byte(v >> 24)
+	w.buf.Write(b[:])
+}
+
+func (w *Writer) writeByte(v byte) {
+	w.buf.WriteByte(v)
+}
+
+func (w *Writer) writeUnsignedByte(v byte) {
+	w.buf.WriteByte(v)
+}
+
+// writeBytes writes n zero bytes, the inverse of Parser.skip.
+func (w *Writer) writeBytes(n int) {
+	if n <= 0 {
+		return
+	}
+	w.buf.Write(make([]byte, n))
+}
+
+// writeByteString writes s into a field width bytes wide, zero-padding or
+// truncating to fit. width <= 0 means the field is exactly len(s) wide,
+// mirroring readByteString's size<=0 case.
+func (w *Writer) writeByteString(s string, width int) {
+	if width <= 0 {
+		width = len(s)
+	}
+	field := make([]byte, width)
+	copy(field, s)
+	w.buf.Write(field)
+}
+
+// writeStringByte is the inverse of readStringByte: a length byte followed
+// by a size-wide (or, if size<=0, len(s)-wide) field holding s.
+func (w *Writer) writeStringByte(s string, size int) {
+	w.writeUnsignedByte(byte(len(s)))
+	w.writeByteString(s, size)
+}
+
+// writeStringByteSizeOfInteger is the inverse of readStringByteSizeOfInteger.
+func (w *Writer) writeStringByteSizeOfInteger(s string) {
+	w.writeUnsignedByte(byte(len(s) + 1))
+	w.writeStringByte(s, len(s))
+}
+
+// writeStringInteger is the inverse of readStringInteger.
+func (w *Writer) writeStringInteger(s string) {
+	w.writeInt(int32(len(s)))
+	w.buf.WriteString(s)
+}
+
+// writeVersionIndexFor picks the VERSIONS entry matching major.minor,
+// falling back to v4.06 (the most capable version WriteSong supports) for
+// anything it cannot match, including gp5 inputs.
+func writeVersionIndexFor(major, minor int) int {
+	want := fmt.Sprintf("FICHIER GUITAR PRO v%d.%02d", major, minor)
+	for i, v := range VERSIONS[:3] {
+		if v == want {
+			return i
+		}
+	}
+	return 2
+}
+
+// WriteSong serializes tab as a gp3/gp4 file and returns its bytes. The
+// version written is whichever of VERSIONS[0:3] (gp3.00, gp4.00, gp4.06)
+// matches tab.Major/tab.Minor, defaulting to gp4.06 otherwise - a gp5
+// TabFile downgrades to gp4.06 rather than failing, since this Writer does
+// not emit the RSE blocks gp5 requires. Use WriteGP5Song instead to keep a
+// gp5 TabFile's lyrics, directions and RSE blocks on round-trip.
+func WriteSong(tab *TabFile) ([]byte, error) {
+	w := NewWriter()
+	w.VersionIndex = writeVersionIndexFor(tab.Major, tab.Minor)
+	profile := w.profile()
+
+	w.writeStringByte(VERSIONS[w.VersionIndex], 30)
+
+	w.writeStringByteSizeOfInteger(tab.Title)
+	w.writeStringByteSizeOfInteger(tab.Subtitle)
+	w.writeStringByteSizeOfInteger(tab.Artist)
+	w.writeStringByteSizeOfInteger(tab.Album)
+	w.writeStringByteSizeOfInteger(tab.LyricsAuthor)
+	w.writeStringByteSizeOfInteger(tab.MusicAuthor)
+	w.writeStringByteSizeOfInteger(tab.Copyright)
+	w.writeStringByteSizeOfInteger(tab.Tab)
+
+	if profile.gp4Plus {
+		w.writeStringByteSizeOfInteger(tab.Instructions)
+
+		var comments []string
+		if tab.Comments != "" {
+			comments = strings.Split(tab.Comments, "\n")
+		}
+		w.writeInt(int32(len(comments)))
+		for _, comment := range comments {
+			w.writeStringInteger(comment)
+		}
+	}
+
+	w.writeInt(int32(tab.TempoValue))
+	if profile.gp4Plus {
+		w.writeByte(0) // humanize tempo: not modeled, left off
+	}
+
+	w.writeByte(byte(tab.GlobalKeySignature - 7)) // inverse of readKeySignature
+	w.writeBytes(1)
+
+	w.writePageSetup(profile)
+
+	w.writeChannels(tab.Channels)
+
+	w.writeInt(int32(len(tab.MeasureHeaders)))
+	w.writeInt(int32(len(tab.Tracks)))
+
+	w.writeMeasureHeaders(tab.MeasureHeaders)
+	w.writeTracks(tab.Tracks)
+
+	for _, track := range tab.Tracks {
+		for mi := range tab.MeasureHeaders {
+			var measure Measure
+			if mi < len(track.Measures) {
+				measure = track.Measures[mi]
+			}
+			w.writeMeasure(measure, profile)
+		}
+	}
+
+	return w.buf.Bytes(), nil
+}
+
+// writePageSetup writes the page setup block readPageSetup skips wholesale;
+// none of it is modeled on TabFile, so it is written as all zeros.
+func (w *Writer) writePageSetup(profile versionProfile) {
+	if profile.gp4Plus {
+		w.writeBytes(49)
+	} else {
+		w.writeBytes(30)
+	}
+	for i := 0; i < 11; i++ {
+		w.writeBytes(4)
+		w.writeStringByte("", 0)
+	}
+}
+
+// writeChannels writes the fixed 64 MIDI channel slots readChannels expects,
+// in the same field order it reads them in.
+func (w *Writer) writeChannels(channels []Channel) {
+	for i := 0; i < 64; i++ {
+		var c Channel
+		if i < len(channels) {
+			c = channels[i]
+		}
+		w.writeInt(c.Program)
+		w.writeByte(c.Volume)
+		w.writeByte(c.Balance)
+		w.writeByte(c.Chorus)
+		w.writeByte(c.Reverb)
+		w.writeByte(c.Pan)
+		w.writeByte(c.Phaser)
+		w.writeByte(c.Tremolo)
+		w.writeBytes(2)
+	}
+}
+
+// writeMeasureHeaders mirrors readMeasureHeaders: it only re-sends a
+// numerator/denominator when it actually changes (or repeatOpen requests
+// it, per the file format's reuse of bit 0x01 for both), so headers that
+// inherit the running time signature round-trip the same way they were read.
+func (w *Writer) writeMeasureHeaders(headers []MeasureHeader) {
+	var previousNumerator, previousDenominator int32 = 4, 4
+
+	for i, header := range headers {
+		if i > 0 {
+			w.writeByte(0) // separator byte
+		}
+
+		numerator := int32(header.TimeSignature.Numerator)
+		denominator := int32(header.TimeSignature.Denominator.Value)
+
+		var flags byte
+		if header.RepeatOpen || numerator != previousNumerator {
+			flags |= 0x01
+		}
+		if denominator != previousDenominator {
+			flags |= 0x02
+		}
+		w.writeByte(flags)
+
+		if flags&0x01 != 0 {
+			w.writeByte(byte(numerator))
+		}
+		if flags&0x02 != 0 {
+			w.writeByte(byte(denominator))
+		}
+		previousNumerator, previousDenominator = numerator, denominator
+	}
+}
+
+// writeTracks mirrors readTracks. Capo and track color are not modeled on
+// Track, so they are written as zero; the GM channel pair is recovered from
+// Track.ChannelID, the same identifier readChannel assigned it with.
+func (w *Writer) writeTracks(tracks []Track) {
+	for _, track := range tracks {
+		w.writeByte(0) // track flags: not modeled
+
+		w.writeStringByte(track.Name, 40)
+
+		w.writeInt(int32(len(track.GuitarStrings)))
+		for s := 0; s < 7; s++ {
+			var tuning int32
+			for _, gs := range track.GuitarStrings {
+				if int(gs.Number) == s+1 {
+					tuning = gs.Value
+				}
+			}
+			w.writeInt(tuning)
+		}
+
+		w.writeBytes(4) // port
+		w.writeTrackChannel(track)
+		w.writeBytes(4) // fret count
+		w.writeInt(0)   // capo: not modeled
+
+		w.writeByte(0) // color.r
+		w.writeByte(0) // color.g
+		w.writeByte(0) // color.b
+		w.writeBytes(1)
+	}
+}
+
+// writeTrackChannel writes the two GM channel indices readChannel collapses
+// into a single Track.ChannelID.
+func (w *Writer) writeTrackChannel(track Track) {
+	gmChannel := track.ChannelID
+	if gmChannel <= 0 {
+		gmChannel = 1
+	}
+	w.writeInt(gmChannel)
+	w.writeInt(gmChannel)
+}
+
+// writeMeasure mirrors readMeasure for profile.voiceCount voices. WriteSong
+// only targets gp3/gp4, whose voiceCount is always 1.
+func (w *Writer) writeMeasure(measure Measure, profile versionProfile) {
+	for voice := 0; voice < profile.voiceCount; voice++ {
+		w.writeInt(int32(len(measure.Beats)))
+		for _, beat := range measure.Beats {
+			w.writeBeat(beat, voice, profile)
+		}
+	}
+}
+
+// writeBeat mirrors readBeat. The beat-level effect (tapping/slapping/pop,
+// fade-in, vibrato, tremolo bar) is read back off the beat's first note,
+// mirroring how readBeat seeds every note in the beat from the same
+// effect value it decodes beat effects into.
+func (w *Writer) writeBeat(beat Beat, voiceIndex int, profile versionProfile) {
+	voice := beat.Voices[voiceIndex]
+	hasChord := beat.Chord.Name != "" || len(beat.Chord.Frets) > 0
+	hasText := beat.Text.Value != ""
+
+	var beatEffect NoteEffect
+	if len(voice.Notes) > 0 {
+		beatEffect = voice.Notes[0].Effect
+	}
+	hasBeatEffects := beatEffectFlags(beat, beatEffect) != [2]byte{}
+	hasMixChange := beat.MixTableChange != nil
+
+	var flags byte = 0x40 // always send an explicit beat type
+	if hasChord {
+		flags |= 0x02
+	}
+	if hasText {
+		flags |= 0x04
+	}
+	if hasBeatEffects {
+		flags |= 0x08
+	}
+	if hasMixChange {
+		flags |= 0x10
+	}
+	w.writeByte(flags)
+
+	var beatType byte
+	if !voice.Empty {
+		beatType = 0x02
+	}
+	w.writeByte(beatType)
+
+	w.writeByte(w.writeDuration(voice.Duration.Value))
+
+	if hasChord {
+		w.writeChord(beat.Chord, profile)
+	}
+	if hasText {
+		w.writeStringByteSizeOfInteger(beat.Text.Value)
+	}
+	if hasBeatEffects {
+		w.writeBeatEffects(beat, beatEffect)
+	}
+	if hasMixChange {
+		w.writeMixChange(*beat.MixTableChange, profile)
+	}
+
+	var stringFlags byte
+	for _, note := range voice.Notes {
+		stringIndex := int(note.String) - 1
+		if stringIndex < 0 || stringIndex > 6 {
+			continue
+		}
+		stringFlags |= 1 << uint(6-stringIndex)
+	}
+	w.writeByte(stringFlags)
+
+	for i := 6; i >= 0; i-- {
+		if stringFlags&(1<<uint(i)) == 0 {
+			continue
+		}
+		stringIndex := 6 - i
+		for _, note := range voice.Notes {
+			if int(note.String)-1 == stringIndex {
+				w.writeNote(note, profile)
+				break
+			}
+		}
+	}
+
+	w.writeBytes(1)
+	w.writeByte(0) // note flags byte: the one optional trailing skip is unused
+}
+
+// writeDuration inverts readDuration/getTime for the common case it is
+// called with: no dotted or tuplet division recorded, since neither
+// survives onto Duration today. b = log2(value*4) - 4, with
+// value = QUARTER_TIME*4/ticks substituted in.
+func (w *Writer) writeDuration(ticks float64) byte {
+	if ticks <= 0 {
+		ticks = QUARTER_TIME
+	}
+	b := math.Round(math.Log2(QUARTER_TIME*16/ticks)) - 4
+	if b < -128 {
+		b = -128
+	}
+	if b > 127 {
+		b = 127
+	}
+	return byte(int8(b)) // half and whole notes are negative, stored as a signed byte
+}
+
+// beatEffectFlags computes the flags1/flags2 bitmask readBeatEffects
+// expects from beat.Stroke and effect's tapping/slapping/pop/fade-in/
+// tremolo bar fields, the same way noteEffectFlags does for note effects.
+func beatEffectFlags(beat Beat, effect NoteEffect) [2]byte {
+	var flags1, flags2 byte
+	if effect.FadeIn {
+		flags1 |= 0x10
+	}
+	if effect.Vibrato {
+		flags1 |= 0x02
+	}
+	if effect.Tapping || effect.Slapping || effect.Pop {
+		flags1 |= 0x20
+	}
+	if len(effect.TremoloBar.Points) > 0 {
+		flags2 |= 0x04
+	}
+	if beat.Stroke.Direction != "" {
+		flags1 |= 0x40
+	}
+	return [2]byte{flags1, flags2}
+}
+
+// writeBeatEffects mirrors readBeatEffects, re-emitting a payload for
+// exactly the fields beatEffectFlags flagged as present.
+func (w *Writer) writeBeatEffects(beat Beat, effect NoteEffect) {
+	flags := beatEffectFlags(beat, effect)
+	flags1, flags2 := flags[0], flags[1]
+	w.writeByte(flags1)
+	w.writeByte(flags2)
+
+	if flags1&0x20 != 0 {
+		var tapEffect byte
+		switch {
+		case effect.Tapping:
+			tapEffect = 1
+		case effect.Slapping:
+			tapEffect = 2
+		case effect.Pop:
+			tapEffect = 3
+		}
+		w.writeUnsignedByte(tapEffect)
+	}
+
+	if flags2&0x04 != 0 {
+		w.writeTremoloBar(effect.TremoloBar)
+	}
+
+	if flags1&0x40 != 0 {
+		var strokeUp, strokeDown byte
+		if beat.Stroke.Direction == "stroke_up" {
+			strokeUp = 1
+		} else {
+			strokeDown = 1
+		}
+		w.writeByte(strokeUp)
+		w.writeByte(strokeDown)
+	}
+}
+
+// writeTremoloBar is the inverse of readTremoloBar: it scales
+// TremoloBar.Points back out of the normalized position/semitone units
+// readTremoloBar leaves them in, the same way writeBend does for Bend.
+func (w *Writer) writeTremoloBar(tremoloBar TremoloBar) {
+	w.writeBytes(5)
+	w.writeInt(int32(len(tremoloBar.Points)))
+	for _, point := range tremoloBar.Points {
+		rawPosition := int32(math.Round(float64(point.Position) *
+			GP_BEND_POSITION / float64(TGEFFECTBEND_MAX_POSITION_LENGTH)))
+		rawValue := int32(math.Round(float64(point.Value) *
+			GP_BEND_SEMITONE / float64(TGEFFECTBEND_SEMITONE_LENGTH)))
+		w.writeInt(rawPosition)
+		w.writeInt(rawValue)
+		w.writeByte(0) // padding byte readTremoloBar discards
+	}
+}
+
+// writeMixChange is the inverse of readMixChange.
+func (w *Writer) writeMixChange(change MixTableChange, profile versionProfile) {
+	var instrument byte = 0xFF
+	if change.Instrument != nil {
+		instrument = byte(*change.Instrument)
+	}
+	w.writeByte(instrument)
+	w.writeBytes(16)
+
+	writeItem := func(item *MixTableItem) byte {
+		if item == nil {
+			return 0xFF
+		}
+		return byte(int8(item.Value))
+	}
+	w.writeByte(writeItem(change.Volume))
+	w.writeByte(writeItem(change.Balance))
+	w.writeByte(writeItem(change.Chorus))
+	w.writeByte(writeItem(change.Reverb))
+	w.writeByte(writeItem(change.Phaser))
+	w.writeByte(writeItem(change.Tremolo))
+
+	w.writeStringByteSizeOfInteger("")
+
+	var tempoValue int32 = -1
+	if change.Tempo != nil {
+		tempoValue = change.Tempo.Value
+	}
+	w.writeInt(tempoValue)
+
+	writeItemDuration := func(item *MixTableItem) {
+		if item == nil {
+			return
+		}
+		w.writeByte(item.Duration)
+	}
+	writeItemDuration(change.Volume)
+	writeItemDuration(change.Balance)
+	writeItemDuration(change.Chorus)
+	writeItemDuration(change.Reverb)
+	writeItemDuration(change.Phaser)
+	writeItemDuration(change.Tremolo)
+
+	if change.Tempo != nil {
+		w.writeByte(change.Tempo.Duration)
+		if profile.gp4Plus {
+			w.writeBytes(1)
+		}
+	}
+
+	if profile.gp4Plus {
+		var allTracksFlags byte
+		if change.AllTracks {
+			allTracksFlags |= 0x01
+		}
+		if change.HideTempo {
+			allTracksFlags |= 0x02
+		}
+		w.writeByte(allTracksFlags)
+		w.writeBytes(1)
+	}
+
+	if profile.gp4Plus {
+		w.writeStringByteSizeOfInteger("")
+		w.writeStringByteSizeOfInteger("")
+	}
+}
+
+// writeChord mirrors readChord and readNewFormatChord in song.go and
+// readOldFormatChord in gp5parser.go.
+func (w *Writer) writeChord(chord Chord, profile versionProfile) {
+	if !profile.hasChordDiagramV2 {
+		w.writeOldFormatChord(chord)
+		return
+	}
+
+	w.writeByte(0x01) // newFormat flag
+
+	w.writeByte(0) // sharp flag: not tracked separately from Root
+	w.writeBytes(3)
+	w.writeByte(byte(chord.Root))
+	w.writeByte(byte(chord.Kind))
+	w.writeByte(byte(chord.Extension))
+	w.writeByte(byte(chord.Bass))
+	w.writeByte(byte(chord.Alternation))
+	w.writeBytes(7) // "add" flag plus reserved bytes, not modelled
+
+	w.writeStringByte(chord.Name, 21)
+	w.writeBytes(4) // fifth/ninth/eleventh alterations; not modelled individually
+
+	w.writeInt(chord.BaseFret)
+	for i := 0; i < 7; i++ {
+		var fret int32
+		if i < len(chord.Frets) {
+			fret = chord.Frets[i]
+			if chord.BaseFret > 0 && fret > 0 {
+				fret -= chord.BaseFret - 1 // undo readNewFormatChord's base-fret fold-in
+			}
+		}
+		w.writeInt(fret)
+	}
+
+	w.writeByte(byte(len(chord.Barres)))
+	for i := 0; i < 2; i++ {
+		var fret byte
+		if i < len(chord.Barres) {
+			fret = byte(chord.Barres[i].Fret)
+		}
+		w.writeByte(fret)
+	}
+	for i := 0; i < 2; i++ {
+		var start byte
+		if i < len(chord.Barres) {
+			start = byte(chord.Barres[i].StartString)
+		}
+		w.writeByte(start)
+	}
+	for i := 0; i < 2; i++ {
+		var end byte
+		if i < len(chord.Barres) {
+			end = byte(chord.Barres[i].EndString)
+		}
+		w.writeByte(end)
+	}
+	w.writeBytes(7) // per-string omission flags; not modelled
+	w.writeBytes(1) // reserved
+
+	for _, finger := range chord.Fingerings {
+		w.writeByte(byte(finger))
+	}
+	if chord.ShowFingering {
+		w.writeByte(1)
+	} else {
+		w.writeByte(0)
+	}
+}
+
+func (w *Writer) writeOldFormatChord(chord Chord) {
+	w.writeBytes(17)
+	w.writeStringByte(chord.Name, 21)
+	w.writeBytes(4)
+	// readOldFormatChord appends this leading int to Frets, as Frets[6].
+	var leading int32
+	if len(chord.Frets) > 6 {
+		leading = chord.Frets[6]
+	}
+	w.writeInt(leading)
+	for i := 0; i < 7; i++ {
+		var fret int32
+		if i < len(chord.Frets) {
+			fret = chord.Frets[i]
+		}
+		w.writeInt(fret)
+	}
+	w.writeBytes(32)
+}
+
+// writeNote mirrors readNote.
+func (w *Writer) writeNote(note Note, profile versionProfile) {
+	flags1, flags2 := noteEffectFlags(note.Effect, profile)
+	hasEffects := flags1 != 0 || flags2 != 0
+
+	var flags byte = 0x20 // always send a note type and a fret
+	if note.Effect.AccentuatedNote {
+		flags |= 0x40
+	}
+	if note.Effect.HeavyAccentuatedNote {
+		flags |= 0x02
+	}
+	if note.Effect.GhostNote {
+		flags |= 0x04
+	}
+	if note.Velocity != 0 {
+		flags |= 0x10
+	}
+	if hasEffects {
+		flags |= 0x08
+	}
+	w.writeByte(flags)
+
+	noteType := byte(0x01)
+	if note.TiedNote {
+		noteType = 0x02
+	} else if note.Effect.DeadNote {
+		noteType = 0x03
+	}
+	w.writeByte(noteType)
+
+	if flags&0x10 != 0 {
+		velocity := (note.Velocity - TGVELOCITIES_MIN_VELOCITY + TGVELOCITIES_VELOCITY_INCREMENT) / TGVELOCITIES_VELOCITY_INCREMENT
+		w.writeByte(byte(velocity))
+	}
+
+	w.writeByte(note.Value)
+
+	w.writeBytes(1)
+
+	if hasEffects {
+		w.writeNoteEffects(flags1, flags2, note.Effect, profile)
+	}
+}
+
+// noteEffectFlags computes the flags1/flags2 bitmask readNoteEffects
+// expects from the effects the TabFile model actually carries, so writeNote
+// can decide whether to set the note's effects bit and writeNoteEffects can
+// write the matching payload from the same values. Fingering (flags2 0x80)
+// is only ever set for profile.hasFingering (gp5); gp3/gp4 never carry it.
+func noteEffectFlags(effect NoteEffect, profile versionProfile) (byte, byte) {
+	var flags1, flags2 byte
+	if profile.hasFingering && (effect.LeftHandFingering != 0 || effect.RightHandFingering != 0) {
+		flags2 |= 0x80
+	}
+	if len(effect.Bend.Points) > 0 {
+		flags1 |= 0x01
+	}
+	if effect.Hammer {
+		flags1 |= 0x02
+	}
+	if effect.LetRing {
+		flags1 |= 0x08
+	}
+	if effect.Grace.Transition != "" {
+		flags1 |= 0x10
+	}
+	if effect.Staccato {
+		flags2 |= 0x01
+	}
+	if effect.PalmMute {
+		flags2 |= 0x02
+	}
+	if effect.TremoloPicking.Duration.Value != "" {
+		flags2 |= 0x04
+	}
+	if effect.Slide {
+		flags2 |= 0x08
+	}
+	if effect.Harmonic.Type != "" {
+		flags2 |= 0x10
+	}
+	if effect.Trill.Duration.Value != "" {
+		flags2 |= 0x20
+	}
+	if effect.Vibrato {
+		flags2 |= 0x40
+	}
+	return flags1, flags2
+}
+
+// writeNoteEffects mirrors readNoteEffects, re-emitting a payload for
+// exactly the effects flags1/flags2 (from noteEffectFlags) flag as present.
+// Fingering (flags2 0x80) only ever appears when profile.hasFingering, i.e.
+// gp5.
+func (w *Writer) writeNoteEffects(flags1, flags2 byte, effect NoteEffect, profile versionProfile) {
+	w.writeByte(flags1)
+	w.writeByte(flags2)
+
+	if flags1&0x01 != 0 {
+		w.writeBend(effect.Bend)
+	}
+	if flags1&0x10 != 0 {
+		w.writeGrace(effect.Grace)
+	}
+	if flags2&0x04 != 0 {
+		w.writeTremoloPicking(effect.TremoloPicking)
+	}
+	if flags2&0x08 != 0 {
+		w.writeByte(0) // slide: readNoteEffects always skips one placeholder byte
+	}
+	if flags2&0x10 != 0 {
+		w.writeHarmonic(effect.Harmonic)
+	}
+	if flags2&0x20 != 0 {
+		w.writeTrill(effect.Trill)
+	}
+	if profile.hasFingering && flags2&0x80 != 0 {
+		w.writeByte(byte(effect.LeftHandFingering))
+		w.writeByte(byte(effect.RightHandFingering))
+	}
+}
+
+// writeBend is the inverse of readBend: it scales Bend.Points back out of
+// the normalized [0, TGEFFECTBEND_MAX_POSITION_LENGTH] position / semitones-
+// over-GP_BEND_SEMITONE value units readBend leaves them in, onto the raw
+// position/value ints the file format stores.
+func (w *Writer) writeBend(bend Bend) {
+	w.writeBytes(5)
+	w.writeInt(int32(len(bend.Points)))
+	for _, point := range bend.Points {
+		rawPosition := int32(math.Round(float64(point.Position) *
+			GP_BEND_POSITION / float64(TGEFFECTBEND_MAX_POSITION_LENGTH)))
+		rawValue := int32(math.Round(float64(point.Value) *
+			GP_BEND_SEMITONE / float64(TGEFFECTBEND_SEMITONE_LENGTH)))
+		w.writeInt(rawPosition)
+		w.writeInt(rawValue)
+		w.writeByte(0) // padding byte readBend discards
+	}
+}
+
+// writeGrace is the inverse of readGrace, including mapping
+// Grace.Transition's string back to the 0-3 byte code readGrace decoded it
+// from.
+func (w *Writer) writeGrace(grace Grace) {
+	w.writeUnsignedByte(grace.Fret)
+
+	dynamicByte := (grace.Dynamic - TGVELOCITIES_MIN_VELOCITY + TGVELOCITIES_VELOCITY_INCREMENT) / TGVELOCITIES_VELOCITY_INCREMENT
+	w.writeUnsignedByte(byte(dynamicByte))
+
+	var transition byte
+	switch grace.Transition {
+	case "slide":
+		transition = 1
+	case "bend":
+		transition = 2
+	case "hammer":
+		transition = 3
+	}
+	w.writeByte(transition)
+
+	w.writeUnsignedByte(grace.Duration)
+
+	var flags byte
+	if grace.Dead {
+		flags |= 0x01
+	}
+	if grace.OnBeat {
+		flags |= 0x02
+	}
+	w.writeUnsignedByte(flags)
+}
+
+// writeTremoloPicking is the inverse of readTremoloPicking.
+func (w *Writer) writeTremoloPicking(tp TremoloPicking) {
+	var value byte
+	switch tp.Duration.Value {
+	case "eighth":
+		value = 1
+	case "sixteenth":
+		value = 2
+	case "thirty_second":
+		value = 3
+	}
+	w.writeUnsignedByte(value)
+}
+
+// writeHarmonic is the inverse of readArtificialHarmonic.
+func (w *Writer) writeHarmonic(harmonic Harmonic) {
+	switch harmonic.Type {
+	case "natural":
+		w.writeByte(1)
+	case "artificial":
+		w.writeByte(2)
+		w.writeBytes(3)
+	case "tapped":
+		w.writeByte(3)
+		w.writeBytes(1)
+	case "pinch":
+		w.writeByte(4)
+	case "semi":
+		w.writeByte(5)
+	default:
+		w.writeByte(0)
+	}
+}
+
+// writeTrill is the inverse of readTrill.
+func (w *Writer) writeTrill(trill Trill) {
+	w.writeByte(trill.Fret)
+
+	var period byte
+	switch trill.Duration.Value {
+	case "sixteenth":
+		period = 1
+	case "thirty_second":
+		period = 2
+	case "sixty_fourth":
+		period = 3
+	}
+	w.writeByte(period)
+}
+
+// Encode serializes tab and writes it to w, dispatching to WriteGP5Song for
+// a gp5 TabFile (Major == 5) and WriteSong for gp3/gp4 otherwise - the same
+// choice Parser.WriteGP5 and a direct WriteSong call already make, exposed
+// as a single entry point that does not require knowing which one applies.
+func Encode(tab TabFile, w io.Writer) error {
+	var data []byte
+	var err error
+	if tab.Major == 5 {
+		data, err = WriteGP5Song(&tab)
+	} else {
+		data, err = WriteSong(&tab)
+	}
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(data)
+	return err
+}
@@ -1,9 +1,12 @@
 // Package parsegp provides functionality for parsing Guitar Pro files (.gp3, .gp4, .gp5, .gpx).
 package parsegp
 
+import "bytes"
+
 // GPFile represents a Guitar Pro file structure.
 type GPFile struct {
 	FullPath       string          `json:"-"`
+	reader         *bytes.Reader   `json:"-"`
 	Version        string          `json:"version"`
 	Title          string          `json:"title"`
 	Subtitle       string          `json:"subtitle"`
@@ -23,6 +26,10 @@ type GPFile struct {
 	TrackCount     int             `json:"trackCount"`
 	MeasureHeaders []MeasureHeader `json:"measureHeaders"`
 	Tracks         []Track         `json:"tracks"`
+	// Binaries holds embedded files from a gp7/gp8 archive (BinaryStylesheet,
+	// bundled audio tracks, ...) keyed by their zip entry name. It is only
+	// populated by loadGPFile.
+	Binaries map[string][]byte `json:"-"`
 }
 
 // Color represents a color in RGB format.
@@ -104,13 +111,44 @@ type Clef struct {
 
 // Beat represents a beat in a Guitar Pro measure.
 type Beat struct {
-	Start  int32   `json:"start"`
-	Voices []Voice `json:"voices"`
-	Stroke Stroke  `json:"stroke"`
-	Pitch  Pitch   `json:"pitch"`
-	Effect Effect  `json:"effect"`
-	Text   Text    `json:"text"`
-	Chord  Chord   `json:"chord"`
+	Start          int32           `json:"start"`
+	Voices         []Voice         `json:"voices"`
+	Stroke         Stroke          `json:"stroke"`
+	Pitch          Pitch           `json:"pitch"`
+	Effect         Effect          `json:"effect"`
+	Text           Text            `json:"text"`
+	Chord          Chord           `json:"chord"`
+	MixTableChange *MixTableChange `json:"mixTableChange,omitempty"`
+}
+
+// MixTableChange represents a mid-measure mix table change: a per-channel
+// adjustment to some combination of volume, balance, chorus, reverb,
+// phaser, tremolo and tempo, each paired with how many ticks the
+// transition to the new value takes. A nil sub-struct means that
+// parameter was left unchanged by this beat.
+type MixTableChange struct {
+	// Instrument is the channel's new MIDI program, or nil if this change
+	// does not include one. Percussion channels ignore program changes, so
+	// it is never set for those (see Parser.isPercussionChannel).
+	Instrument *int8
+	Volume     *MixTableItem
+	Balance    *MixTableItem
+	Chorus     *MixTableItem
+	Reverb     *MixTableItem
+	Phaser     *MixTableItem
+	Tremolo    *MixTableItem
+	Tempo      *MixTableItem
+	// HideTempo and AllTracks come from the applied-to-all bitmask byte
+	// GP4/GP5 append; gp3 mix changes never set either.
+	HideTempo bool
+	AllTracks bool
+}
+
+// MixTableItem pairs a mix table change's target value with the number of
+// ticks the transition to it takes.
+type MixTableItem struct {
+	Value    int32
+	Duration byte
 }
 
 type Text struct {
@@ -173,6 +211,10 @@ type NoteEffect struct {
 	TremoloPicking       TremoloPicking
 	Harmonic             Harmonic
 	Trill                Trill
+	// LeftHandFingering and RightHandFingering are only present in gp5;
+	// gp3/gp4 leave them at their zero value.
+	LeftHandFingering  int8
+	RightHandFingering int8
 }
 
 type Trill struct {
@@ -234,6 +276,46 @@ type Chord struct {
 	Name    string          `json:"name"`
 	Strings *[]GuitarString `json:"strings"`
 	Frets   []int32         `json:"fret"`
+	// BaseFret is the fret every non-muted string in Frets is offset from.
+	// It is only meaningful for gp4/gp5's new-format diagrams; gp3's old
+	// format has no base fret and leaves this 0.
+	BaseFret int32 `json:"baseFret,omitempty"`
+	// Fingerings holds which left hand finger frets each string in Frets,
+	// in the same order: -2 means the string is muted (X), -1 means no
+	// finger is assigned (open or not fretted), 0 is the thumb and 1..4
+	// are the index through little fingers. Only new-format diagrams set
+	// this; it is left at its zero value ([6]int8{}, all zeros) otherwise.
+	Fingerings [6]int8 `json:"fingerings,omitempty"`
+	// Barres lists the barre spans a new-format diagram's chord-frame
+	// data describes, if any.
+	Barres []BarreSpan `json:"barres,omitempty"`
+	// ShowDiagram and ShowFingering mirror the two display flags gp4/gp5
+	// store alongside a new-format diagram: whether the chord frame is
+	// drawn at all, and whether finger numbers are drawn inside it.
+	ShowDiagram   bool `json:"showDiagram,omitempty"`
+	ShowFingering bool `json:"showFingering,omitempty"`
+	// Root, Bass, Kind, Extension and Alternation are gp4/gp5's own
+	// chord-recognition fields - the root/bass pitch classes, harmonic
+	// kind (major/minor/dominant/...), extension (7/9/11/13) and
+	// alteration/addition markers the Guitar Pro chord editor stores
+	// alongside a new-format diagram. They are raw values straight off
+	// the wire: this package does not know Guitar Pro's enumeration for
+	// them, so chord.Analyze (see the chord package) derives an
+	// equivalent label from Frets and a track's tuning instead of
+	// decoding these.
+	Root        int8 `json:"root,omitempty"`
+	Bass        int8 `json:"bass,omitempty"`
+	Kind        int8 `json:"kind,omitempty"`
+	Extension   int8 `json:"extension,omitempty"`
+	Alternation int8 `json:"alternation,omitempty"`
+}
+
+// BarreSpan is one barre a chord diagram's chord-frame data describes: a
+// fret held across every string from StartString to EndString inclusive.
+type BarreSpan struct {
+	Fret        int32 `json:"fret"`
+	StartString int32 `json:"startString"`
+	EndString   int32 `json:"endString"`
 }
 
 type Duration struct {
@@ -290,6 +372,21 @@ type TabFile struct {
 	TrackCount         int
 	MeasureHeaders     []MeasureHeader
 	Tracks             []Track
+	// Warnings lists the fields Parser.Parse could not read when the
+	// Parser was not run in Strict mode.
+	Warnings []ParseWarning
+	// ParseErrors is Warnings' position-aware counterpart: the same failed
+	// reads, each additionally located by track/measure/beat.
+	ParseErrors []ParseError
+	// TempoName, HideTempo, Key, Octave, Directions and MasterReverb are
+	// only present in gp5; they are left at their zero value for gp3/gp4
+	// files.
+	TempoName    string
+	HideTempo    bool
+	Key          int
+	Octave       int8
+	Directions   map[string]int16
+	MasterReverb int32
 }
 
 // notGPFile represents an error indicating the file is not a Guitar Pro file.
@@ -0,0 +1,265 @@
+// Description: This file adds an event-driven alternative to Parse for
+// callers who want to process a Guitar Pro file beat by beat instead of
+// waiting for a fully assembled TabFile - indexing a large tab library,
+// or piping beats straight into something like the midi package's
+// realtime Player, without holding every note of every file in memory.
+
+package parsegp
+
+// SongHeader carries the song-level metadata Parse copies onto TabFile,
+// minus Lyric and Comments, which no Visitor callback reports, and
+// Channels, MeasureHeaders and Tracks, which get their own Visitor
+// callbacks once read. Walk builds one from the Parser once
+// readSongStructure reaches the point Parse itself fills these in from.
+type SongHeader struct {
+	Major, Minor                          int
+	Title, Subtitle, Artist, Album        string
+	LyricsAuthor, MusicAuthor, Copyright  string
+	Tab, Instructions                     string
+	TempoValue                            int
+	GlobalKeySignature                    int
+	TempoName                             string
+	HideTempo                             bool
+	Key                                   int
+	Octave                                int8
+	Directions                            map[string]int16
+	MasterReverb                          int32
+}
+
+// Visitor receives the structural events Walk fires as it decodes a
+// Guitar Pro file, in the order they occur in the file: OnHeader once,
+// then OnChannel per channel and OnMeasureHeader per measure header, then
+// OnTrack per track, then for every beat in every measure of every track
+// OnMixChange (if the beat carries one), OnNote and OnNoteEffect (per note,
+// if the note carries one) and finally OnBeat for the beat as a whole.
+// OnBeat fires each time a voice of the beat has been read, so in gp5,
+// which reads two voices per measure, a beat present in both voices
+// reaches OnBeat twice, the second time with both voices filled in.
+// Any method returning a non-nil error aborts the walk; Parse and Walk
+// both surface that error to their caller unchanged.
+type Visitor interface {
+	OnHeader(header SongHeader) error
+	OnChannel(channel Channel) error
+	OnMeasureHeader(header MeasureHeader) error
+	OnTrack(track Track) error
+	OnBeat(trackNumber, measureNumber int, beat Beat) error
+	OnNote(note Note) error
+	OnNoteEffect(effect NoteEffect) error
+	OnMixChange(change MixTableChange) error
+}
+
+func (p *Parser) fireHeader(header SongHeader) error {
+	if p.Visitor == nil {
+		return nil
+	}
+	return p.Visitor.OnHeader(header)
+}
+
+func (p *Parser) fireChannel(channel Channel) error {
+	if p.Visitor == nil {
+		return nil
+	}
+	return p.Visitor.OnChannel(channel)
+}
+
+func (p *Parser) fireMeasureHeader(header MeasureHeader) error {
+	if p.Visitor == nil {
+		return nil
+	}
+	return p.Visitor.OnMeasureHeader(header)
+}
+
+func (p *Parser) fireTrack(track Track) error {
+	if p.Visitor == nil {
+		return nil
+	}
+	return p.Visitor.OnTrack(track)
+}
+
+func (p *Parser) fireBeat(trackNumber, measureNumber int, beat Beat) error {
+	if p.Visitor == nil {
+		return nil
+	}
+	return p.Visitor.OnBeat(trackNumber, measureNumber, beat)
+}
+
+func (p *Parser) fireNote(note Note) error {
+	if p.Visitor == nil {
+		return nil
+	}
+	return p.Visitor.OnNote(note)
+}
+
+func (p *Parser) fireNoteEffect(effect NoteEffect) error {
+	if p.Visitor == nil {
+		return nil
+	}
+	return p.Visitor.OnNoteEffect(effect)
+}
+
+func (p *Parser) fireMixChange(change MixTableChange) error {
+	if p.Visitor == nil {
+		return nil
+	}
+	return p.Visitor.OnMixChange(change)
+}
+
+// NopVisitor implements Visitor with methods that all return nil and do
+// nothing. Embed it in a Visitor that only cares about one or two of the
+// eight callbacks, and override just those, instead of writing out the
+// other seven no-ops by hand - see TempoMapVisitor and
+// ChordHistogramVisitor below.
+type NopVisitor struct{}
+
+func (NopVisitor) OnHeader(SongHeader) error                { return nil }
+func (NopVisitor) OnChannel(Channel) error                  { return nil }
+func (NopVisitor) OnMeasureHeader(MeasureHeader) error      { return nil }
+func (NopVisitor) OnTrack(Track) error                      { return nil }
+func (NopVisitor) OnBeat(int, int, Beat) error               { return nil }
+func (NopVisitor) OnNote(Note) error                        { return nil }
+func (NopVisitor) OnNoteEffect(NoteEffect) error             { return nil }
+func (NopVisitor) OnMixChange(MixTableChange) error          { return nil }
+
+// Collector is the default Visitor: it reassembles the same
+// Channels/MeasureHeaders/Tracks-with-Measures shape Parse returns on
+// TabFile, just built from Walk's callbacks instead of Parse's own
+// bookkeeping. Pass a *Collector to Walk when the event-driven entry
+// point is wanted (for example, to reuse a Visitor-based pipeline file by
+// file) but the end result should still look like a conventional TabFile.
+type Collector struct {
+	Header         SongHeader
+	Channels       []Channel
+	MeasureHeaders []MeasureHeader
+	Tracks         []Track
+
+	trackByNumber   map[int]int
+	measureByNumber map[[2]int]int
+}
+
+// NewCollector returns a ready-to-use Collector.
+func NewCollector() *Collector {
+	return &Collector{
+		trackByNumber:   make(map[int]int),
+		measureByNumber: make(map[[2]int]int),
+	}
+}
+
+func (c *Collector) OnHeader(header SongHeader) error {
+	c.Header = header
+	return nil
+}
+
+func (c *Collector) OnChannel(channel Channel) error {
+	c.Channels = append(c.Channels, channel)
+	return nil
+}
+
+func (c *Collector) OnMeasureHeader(header MeasureHeader) error {
+	c.MeasureHeaders = append(c.MeasureHeaders, header)
+	return nil
+}
+
+func (c *Collector) OnTrack(track Track) error {
+	c.trackByNumber[track.Number] = len(c.Tracks)
+	c.Tracks = append(c.Tracks, track)
+	return nil
+}
+
+// OnBeat appends beat to trackNumber's measureNumber, creating both the
+// measure (looked up by number in MeasureHeaders, which OnMeasureHeader
+// must have already collected by the time any beat fires) and its slot in
+// Tracks the first time either is seen. A beat whose Start that measure
+// already holds replaces the earlier copy instead of being appended again.
+func (c *Collector) OnBeat(trackNumber, measureNumber int, beat Beat) error {
+	trackIdx, ok := c.trackByNumber[trackNumber]
+	if !ok {
+		return nil
+	}
+	track := &c.Tracks[trackIdx]
+
+	key := [2]int{trackNumber, measureNumber}
+	measureIdx, ok := c.measureByNumber[key]
+	if !ok {
+		var header MeasureHeader
+		for _, h := range c.MeasureHeaders {
+			if h.Number == measureNumber {
+				header = h
+				break
+			}
+		}
+		track.Measures = append(track.Measures, Measure{Header: header, Start: header.Start})
+		measureIdx = len(track.Measures) - 1
+		c.measureByNumber[key] = measureIdx
+	}
+
+	beats := track.Measures[measureIdx].Beats
+	for i := range beats {
+		if beats[i].Start == beat.Start {
+			beats[i] = beat // a later voice of a beat already collected
+			return nil
+		}
+	}
+	track.Measures[measureIdx].Beats = append(beats, beat)
+	return nil
+}
+
+// OnNote and OnNoteEffect are no-ops for Collector: readNote already
+// nests both onto the Beat that OnBeat receives, so repeating them here
+// would duplicate data rather than add it.
+func (c *Collector) OnNote(Note) error               { return nil }
+func (c *Collector) OnNoteEffect(NoteEffect) error    { return nil }
+func (c *Collector) OnMixChange(MixTableChange) error { return nil }
+
+// TempoMapEntry is one entry in TempoMapVisitor's tempo map: the song's
+// starting tempo (MeasureNumber 0) or a later mid-measure tempo change.
+type TempoMapEntry struct {
+	MeasureNumber int
+	Value         int32
+}
+
+// TempoMapVisitor collects only the points in a song where the tempo is
+// set or changes, ignoring every note, chord and effect - building a
+// tempo map for a library of tabs this way never holds a single Beat's
+// worth of notes in memory.
+type TempoMapVisitor struct {
+	NopVisitor
+	Entries []TempoMapEntry
+}
+
+func (v *TempoMapVisitor) OnHeader(header SongHeader) error {
+	v.Entries = append(v.Entries, TempoMapEntry{Value: int32(header.TempoValue)})
+	return nil
+}
+
+func (v *TempoMapVisitor) OnBeat(_, measureNumber int, beat Beat) error {
+	if beat.MixTableChange == nil || beat.MixTableChange.Tempo == nil {
+		return nil
+	}
+	v.Entries = append(v.Entries, TempoMapEntry{
+		MeasureNumber: measureNumber,
+		Value:         beat.MixTableChange.Tempo.Value,
+	})
+	return nil
+}
+
+// ChordHistogramVisitor tallies how often each named chord diagram
+// appears across a song - or a whole library, if the same visitor is
+// reused across several Walk calls - without keeping a single Measure
+// around once it has been counted.
+type ChordHistogramVisitor struct {
+	NopVisitor
+	Counts map[string]int
+}
+
+// NewChordHistogramVisitor returns a ready-to-use ChordHistogramVisitor.
+func NewChordHistogramVisitor() *ChordHistogramVisitor {
+	return &ChordHistogramVisitor{Counts: make(map[string]int)}
+}
+
+func (v *ChordHistogramVisitor) OnBeat(_, _ int, beat Beat) error {
+	if beat.Chord.Name == "" {
+		return nil
+	}
+	v.Counts[beat.Chord.Name]++
+	return nil
+}
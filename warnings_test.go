@@ -0,0 +1,56 @@
+package parsegp
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestSectionBeforeMeasureLoop(t *testing.T) {
+	p := &Parser{}
+	if got, want := p.section("channels"), "channels"; got != want {
+		t.Fatalf("section(%q) = %q, want %q", "channels", got, want)
+	}
+}
+
+func TestSectionWithinMeasureLoop(t *testing.T) {
+	p := &Parser{curTrack: 2, curMeasure: 5, curBeat: 1}
+	got := p.section("mixChange.volume")
+	want := "track 2 / measure 5 / beat 1 / mixChange.volume"
+	if got != want {
+		t.Fatalf("section(...) = %q, want %q", got, want)
+	}
+}
+
+func TestFailAppendsWarningAndPositionedParseError(t *testing.T) {
+	p := &Parser{curTrack: 1, curMeasure: 3, curBeat: 2, BufferPosition: 42}
+	err := p.fail("beat.duration", errors.New("boom"))
+	if err != nil {
+		t.Fatalf("fail in non-strict mode returned %v, want nil", err)
+	}
+
+	if len(p.Warnings) != 1 || p.Warnings[0].Field != "beat.duration" {
+		t.Fatalf("Warnings = %+v, want one entry for beat.duration", p.Warnings)
+	}
+
+	if len(p.ParseErrors) != 1 {
+		t.Fatalf("ParseErrors = %+v, want exactly one entry", p.ParseErrors)
+	}
+	pe := p.ParseErrors[0]
+	if pe.Offset != 42 {
+		t.Errorf("ParseErrors[0].Offset = %d, want 42", pe.Offset)
+	}
+	if want := "track 1 / measure 3 / beat 2 / beat.duration"; pe.Section != want {
+		t.Errorf("ParseErrors[0].Section = %q, want %q", pe.Section, want)
+	}
+}
+
+func TestFailInStrictModeReturnsErrorWithoutRecording(t *testing.T) {
+	p := &Parser{Strict: true}
+	err := p.fail("beat.duration", errors.New("boom"))
+	if err == nil {
+		t.Fatal("fail in strict mode returned nil, want an error")
+	}
+	if len(p.Warnings) != 0 || len(p.ParseErrors) != 0 {
+		t.Fatalf("Strict mode recorded Warnings=%v ParseErrors=%v, want none", p.Warnings, p.ParseErrors)
+	}
+}
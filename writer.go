@@ -0,0 +1,137 @@
+package parsegp
+
+import (
+	"bufio"
+	"encoding/binary"
+	"io"
+	"os"
+)
+
+// writeLongString writes a string using the same length-prefixed format
+// that readLongString decodes: a 4-byte little-endian size (size = len+1),
+// a redundant length byte, followed by the string bytes themselves.
+func writeLongString(w io.Writer, s string) error {
+	size := int32(len(s) + 1)
+	if err := binary.Write(w, binary.LittleEndian, size); err != nil {
+		return err
+	}
+	if _, err := w.Write([]byte{byte(len(s))}); err != nil {
+		return err
+	}
+	_, err := w.Write([]byte(s))
+	return err
+}
+
+// Save encodes the GPFile and writes it to path, overwriting any existing
+// file. It is a convenience wrapper around Encode for callers that do not
+// need control over the destination io.Writer.
+func (gp *GPFile) Save(path string) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	bw := bufio.NewWriter(f)
+	if err := gp.Encode(bw); err != nil {
+		return err
+	}
+	return bw.Flush()
+}
+
+// Encode writes gp's header/metadata block only - the FICHIER GUITAR PRO
+// banner, the version string, and the Title/Artist/Subtitle/Album/
+// LyricsAuthor/MusicAuthor/Copyright/Tab/Instructions fields read by
+// uncompressedGpInfo, in the same field order LoadHeader expects them back
+// in. This is a deliberate, fixed scope, not an oversight: Encode does not
+// serialize tracks, measures, beats or any other body content, and it does
+// not preserve the raw bytes of a source file's body. A GPFile written by
+// Encode and re-decoded will match the original on every header field this
+// package populates, but LoadBody on the result starts from whatever
+// Encode/Save produced, which today is header-only. Round-tripping the
+// body is out of scope until LoadBody itself covers the full gp3/gp4/gp5
+// body format (and gpx bodies at all, see LoadBody's gpx error).
+func (gp *GPFile) Encode(w io.Writer) error {
+	banner := "FICHIER GUITAR PRO "
+	if err := writeGPByteString(w, banner, 30); err != nil {
+		return err
+	}
+
+	version := gp.Version
+	if version == "" {
+		version = "v5.10"
+	}
+	// uncompressedGpInfo reads the version as a fixed 4-byte field, not a
+	// length-prefixed string, so it is written with writeFixedString here
+	// rather than writeGPByteString.
+	if err := writeFixedString(w, version, 4); err != nil {
+		return err
+	}
+
+	// uncompressedGpInfo seeks to an absolute offset after the version
+	// string (skipping one padding byte this package does not model) and
+	// then, depending on version, skips a further 3 bytes for the old
+	// 1.0x/1T formats or 1 byte otherwise before the Title field. Mirror
+	// both skips here so Title and the fields after it land at the offset
+	// LoadHeader expects.
+	padding := 1
+	switch version {
+	case "1T\x03\x04", "1.04", "1.02", "1.03":
+		padding = 1 + 3
+	default:
+		padding = 1 + 1
+	}
+	if _, err := w.Write(make([]byte, padding)); err != nil {
+		return err
+	}
+
+	fields := []string{
+		gp.Title,
+		gp.Artist,
+		gp.Subtitle,
+		gp.Album,
+		gp.LyricsAuthor,
+		gp.MusicAuthor,
+		gp.Copyright,
+		gp.Tab,
+	}
+	for _, field := range fields {
+		if err := writeLongString(w, field); err != nil {
+			return err
+		}
+	}
+
+	if isGP5(gp.Version) {
+		if err := writeLongString(w, gp.Instructions); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// writeGPByteString writes a fixed-size, length-prefixed string field such
+// as the FICHIER GUITAR PRO banner: one length byte followed by the
+// string's own bytes, truncated to at most size-1 and not zero padded out
+// to size-1, to match what uncompressedGpInfo expects to read back.
+func writeGPByteString(w io.Writer, s string, size int) error {
+	if len(s) > size-1 {
+		s = s[:size-1]
+	}
+	if _, err := w.Write([]byte{byte(len(s))}); err != nil {
+		return err
+	}
+	_, err := w.Write([]byte(s))
+	return err
+}
+
+// writeFixedString writes s as exactly size raw bytes with no length
+// prefix: truncated if too long, zero-padded if too short. This matches
+// uncompressedGpInfo's version field, which is read with a plain
+// io.ReadFull into a fixed-size buffer rather than through readLongString.
+func writeFixedString(w io.Writer, s string, size int) error {
+	buf := make([]byte, size)
+	copy(buf, s)
+	_, err := w.Write(buf)
+	return err
+}
@@ -0,0 +1,129 @@
+package parsegp
+
+import (
+	"bytes"
+	"testing"
+)
+
+// TestEncodeDecodeRoundTrip writes a GPFile's header with Encode, decodes it
+// back with NewGPFileFromReader/LoadHeader, and checks that every field
+// Encode claims to cover survives the round trip unchanged.
+func TestEncodeDecodeRoundTrip(t *testing.T) {
+	cases := []struct {
+		name string
+		gp   GPFile
+	}{
+		{
+			name: "gp5 with instructions",
+			gp: GPFile{
+				Version:      "v5.1",
+				Title:        "Test Title",
+				Artist:       "Test Artist",
+				Subtitle:     "Test Subtitle",
+				Album:        "Test Album",
+				LyricsAuthor: "Lyric Writer",
+				MusicAuthor:  "Music Writer",
+				Copyright:    "(c) 2026",
+				Tab:          "Transcriber Name",
+				Instructions: "Play it loud",
+			},
+		},
+		{
+			name: "gp4 without instructions",
+			gp: GPFile{
+				Version:      "v4.0",
+				Title:        "Other Title",
+				Artist:       "Other Artist",
+				Subtitle:     "",
+				Album:        "Other Album",
+				LyricsAuthor: "",
+				MusicAuthor:  "Other Music Writer",
+				Copyright:    "",
+				Tab:          "Other Tab",
+			},
+		},
+		{
+			name: "empty fields",
+			gp:   GPFile{Version: "v5.0"},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := tc.gp.Encode(&buf); err != nil {
+				t.Fatalf("Encode: %v", err)
+			}
+
+			decoded, err := NewGPFileFromReader("roundtrip.gp5", bytes.NewReader(buf.Bytes()))
+			if err != nil {
+				t.Fatalf("NewGPFileFromReader: %v", err)
+			}
+			if err := decoded.LoadHeader(); err != nil {
+				t.Fatalf("LoadHeader: %v", err)
+			}
+
+			if decoded.Version != tc.gp.Version {
+				t.Errorf("Version = %q, want %q", decoded.Version, tc.gp.Version)
+			}
+			if decoded.Title != tc.gp.Title {
+				t.Errorf("Title = %q, want %q", decoded.Title, tc.gp.Title)
+			}
+			if decoded.Artist != tc.gp.Artist {
+				t.Errorf("Artist = %q, want %q", decoded.Artist, tc.gp.Artist)
+			}
+			if decoded.Subtitle != tc.gp.Subtitle {
+				t.Errorf("Subtitle = %q, want %q", decoded.Subtitle, tc.gp.Subtitle)
+			}
+			if decoded.Album != tc.gp.Album {
+				t.Errorf("Album = %q, want %q", decoded.Album, tc.gp.Album)
+			}
+			if decoded.LyricsAuthor != tc.gp.LyricsAuthor {
+				t.Errorf("LyricsAuthor = %q, want %q", decoded.LyricsAuthor, tc.gp.LyricsAuthor)
+			}
+			if decoded.MusicAuthor != tc.gp.MusicAuthor {
+				t.Errorf("MusicAuthor = %q, want %q", decoded.MusicAuthor, tc.gp.MusicAuthor)
+			}
+			if decoded.Copyright != tc.gp.Copyright {
+				t.Errorf("Copyright = %q, want %q", decoded.Copyright, tc.gp.Copyright)
+			}
+			if decoded.Tab != tc.gp.Tab {
+				t.Errorf("Tab = %q, want %q", decoded.Tab, tc.gp.Tab)
+			}
+			if isGP5(tc.gp.Version) && decoded.Instructions != tc.gp.Instructions {
+				t.Errorf("Instructions = %q, want %q", decoded.Instructions, tc.gp.Instructions)
+			}
+		})
+	}
+}
+
+// TestSaveDecodeRoundTrip exercises Save/NewGPFile the same way a caller
+// writing to and reading back from disk would, rather than going through
+// Encode/NewGPFileFromReader directly.
+func TestSaveDecodeRoundTrip(t *testing.T) {
+	gp := GPFile{
+		Version: "v5.0",
+		Title:   "Saved Title",
+		Artist:  "Saved Artist",
+	}
+
+	path := t.TempDir() + "/roundtrip.gp5"
+	if err := gp.Save(path); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+
+	decoded, err := NewGPFile(path)
+	if err != nil {
+		t.Fatalf("NewGPFile: %v", err)
+	}
+	if err := decoded.LoadHeader(); err != nil {
+		t.Fatalf("LoadHeader: %v", err)
+	}
+
+	if decoded.Title != gp.Title {
+		t.Errorf("Title = %q, want %q", decoded.Title, gp.Title)
+	}
+	if decoded.Artist != gp.Artist {
+		t.Errorf("Artist = %q, want %q", decoded.Artist, gp.Artist)
+	}
+}